@@ -22,10 +22,11 @@ var AppGeneratorSubCommands = cli.Commands{
 		},
 	},
 	{
-		Name:     "compiler",
-		HelpName: "deer-executor example compiler",
-		Action:   generate.MakeCompileConfigFile,
-		Usage:    "generate compiler settings file",
+		Name:     "languages",
+		HelpName: "deer-executor example languages",
+		Aliases:  []string{"compiler"},
+		Action:   generate.MakeLanguagesConfigFile,
+		Usage:    "generate languages registry file",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "output",
@@ -2,7 +2,6 @@ package generate
 
 import (
 	"fmt"
-	"github.com/LanceLRQ/deer-executor/v2/common/constants"
 	"github.com/LanceLRQ/deer-executor/v2/common/provider"
 	"github.com/LanceLRQ/deer-executor/v2/common/utils"
 	"github.com/urfave/cli/v2"
@@ -10,12 +9,12 @@ import (
 	"os"
 )
 
-// MakeCompileConfigFile 生成编译器配置(程序使用)
-func MakeCompileConfigFile(c *cli.Context) error {
-	config := provider.CompileCommands
+// MakeLanguagesConfigFile 生成语言注册表配置(程序使用)
+func MakeLanguagesConfigFile(c *cli.Context) error {
+	config := provider.RegisteredLanguages()
 	output := c.String("output")
 	if output == "" {
-		output = "./compilers.json"
+		output = "./languages.json"
 	}
 	s, err := os.Stat(output)
 	if s != nil || os.IsExist(err) {
@@ -37,7 +36,10 @@ func MakeCompileConfigFile(c *cli.Context) error {
 
 // MakeJITMemoryConfigFile 生成JIT内存宽限配置
 func MakeJITMemoryConfigFile(c *cli.Context) error {
-	config := constants.MemorySizeForJIT
+	config := map[string]int{}
+	for _, lang := range provider.RegisteredLanguages() {
+		config[lang.Name] = provider.GetJITMemory(lang.Name)
+	}
 	output := c.String("output")
 	if output == "" {
 		output = "./jit_memory.json"
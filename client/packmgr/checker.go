@@ -14,7 +14,6 @@ import (
 	"os"
 	"path"
 	"strings"
-	"time"
 )
 
 func runCheckerCase(session *executor.JudgeSession, caseIndex int) error {
@@ -44,7 +43,8 @@ func runCheckerCase(session *executor.JudgeSession, caseIndex int) error {
 		return err
 	}
 	// 运行checker程序
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), getCheckerTimeout(&config))
+	defer cancel()
 	// <input-file> <output-file> <answer-file> [<report-file>]
 	ret, err := utils.RunUnixShell(&structs.ShellOptions{
 		Context: ctx,
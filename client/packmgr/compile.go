@@ -8,14 +8,91 @@ import (
 	"github.com/LanceLRQ/deer-executor/v2/executor"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
-// 针对Testlib支持的编译方法
-func compileTestlibCodeFile(source, name, binRoot, configDir, libraryDir, typeName string) error {
-	fmt.Printf("build %s [%s]...", typeName, name)
+// 获取testlib程序的语言，未设置时按文件扩展名识别，c/c++或无法识别时使用g++
+func getTestlibLanguage(lang, source string) string {
+	if lang != "" && lang != "auto" {
+		return lang
+	}
+	switch ext := strings.TrimPrefix(path.Ext(source), "."); ext {
+	case "", "c", "cc", "cpp", "cxx":
+		return "g++"
+	default:
+		return ext
+	}
+}
+
+// 获取c/c++语言(包括各个语言版本)的testlib编译器，c语言也使用g++编译，其他语言返回nil
+func getTestlibCppCompiler(lang string) *provider.GenericCompileProvider {
+	compiler, err := provider.NewCompileProvider(lang)
+	if err != nil {
+		return nil
+	}
+	switch provider.GetLanguageFamily(compiler.Name) {
+	case "gcc":
+		// testlib只支持c++
+		compiler, err = provider.NewCompileProvider("g++")
+		if err != nil {
+			return nil
+		}
+		return compiler
+	case "g++":
+		return compiler
+	}
+	return nil
+}
+
+// 写入启动脚本，使非二进制的程序也可以直接通过bin目录下的文件名调用
+func writeLauncherScript(target string, args []string) error {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+	}
+	content := fmt.Sprintf("#!/bin/sh\nexec %s \"$@\"\n", strings.Join(quoted, " "))
+	return ioutil.WriteFile(target, []byte(content), 0775)
+}
+
+// 使用对应语言的编译提供程序编译，并生成启动脚本
+func compileWithProvider(genCodeFile, compileTarget, lang string, compileTimeout int) (bool, string, error) {
+	compiler, err := executor.MatchCodeLanguage(lang, genCodeFile)
+	if err != nil {
+		return false, "", err
+	}
+	code, err := ioutil.ReadFile(genCodeFile)
+	if err != nil {
+		return false, "", err
+	}
+	// 每个程序使用单独的目录存放源码和编译结果
+	workDir := compileTarget + "_src"
+	_ = os.RemoveAll(workDir)
+	if err = os.MkdirAll(workDir, 0775); err != nil {
+		return false, "", err
+	}
+	if err = compiler.Init(string(code), workDir); err != nil {
+		return false, "", err
+	}
+	compiler.SetCompileTimeout(compileTimeout)
+	ok, ceinfo := compiler.Compile()
+	if !ok {
+		return false, ceinfo, nil
+	}
+	_ = os.Remove(compileTarget)
+	if err = writeLauncherScript(compileTarget, compiler.GetRunArgs()); err != nil {
+		return false, "", err
+	}
+	return true, "", nil
+}
+
+// 针对Testlib支持的编译方法，compileTimeout为编译的超时时间(ms)，0表示只受语言的编译资源限制约束
+func compileTestlibCodeFile(source, name, binRoot, configDir, libraryDir, typeName, lang string, compileTimeout int) error {
+	lang = getTestlibLanguage(lang, source)
+	fmt.Printf("build %s [%s] (%s)...", typeName, name, lang)
 	prefix, ok := constants.TestlibBinaryPrefixs[typeName]
 	if !ok {
 		prefix = ""
@@ -26,8 +103,26 @@ func compileTestlibCodeFile(source, name, binRoot, configDir, libraryDir, typeNa
 	if err != nil && os.IsNotExist(err) {
 		return errors.Errorf("cannot find %s's source code", typeName)
 	}
-	compiler := provider.NewGnucppCompileProvider()
-	ok, ceinfo := compiler.ManualCompile(genCodeFile, compileTarget, []string{libraryDir})
+	var ceinfo string
+	cppCompiler := getTestlibCppCompiler(lang)
+	switch {
+	case cppCompiler != nil:
+		cppCompiler.SetCompileTimeout(compileTimeout)
+		ok, ceinfo = cppCompiler.ManualCompile(genCodeFile, compileTarget, []string{libraryDir})
+	case lang == "go" || lang == "golang":
+		compiler, err := provider.NewCompileProvider("golang")
+		if err != nil {
+			return err
+		}
+		compiler.SetCompileTimeout(compileTimeout)
+		ok, ceinfo = compiler.ManualCompile(genCodeFile, compileTarget, nil)
+	default:
+		ok, ceinfo, err = compileWithProvider(genCodeFile, compileTarget, lang, compileTimeout)
+		if err != nil {
+			fmt.Println("Error.")
+			return err
+		}
+	}
 	if ok {
 		fmt.Println("Done.")
 	} else {
@@ -42,10 +137,11 @@ func compileWorkCodeFiles(config structs.JudgeConfiguration, libraryDir string)
 	if err != nil {
 		return err
 	}
+	compileTimeout := executor.GetTimeouts(&config).Compile
 	// Generators
 	if config.TestLib.Generators != nil {
 		for _, gen := range config.TestLib.Generators {
-			err = compileTestlibCodeFile(gen.Source, gen.Name, binRoot, config.ConfigDir, libraryDir, "generator")
+			err = compileTestlibCodeFile(gen.Source, gen.Name, binRoot, config.ConfigDir, libraryDir, "generator", gen.Language, compileTimeout)
 			if err != nil {
 				return err
 			}
@@ -53,7 +149,16 @@ func compileWorkCodeFiles(config structs.JudgeConfiguration, libraryDir string)
 	}
 	// Validator
 	if config.TestLib.Validator != "" && config.TestLib.ValidatorName != "" {
-		err = compileTestlibCodeFile(config.TestLib.Validator, config.TestLib.ValidatorName, binRoot, config.ConfigDir, libraryDir, "validator")
+		err = compileTestlibCodeFile(
+			config.TestLib.Validator,
+			config.TestLib.ValidatorName,
+			binRoot,
+			config.ConfigDir,
+			libraryDir,
+			"validator",
+			config.TestLib.ValidatorLanguage,
+			compileTimeout,
+		)
 		if err != nil {
 			return err
 		}
@@ -67,7 +172,7 @@ func compileWorkCodeFiles(config structs.JudgeConfiguration, libraryDir string)
 			return errors.Errorf("please setup special judge checker")
 		}
 		checkerType := "checker"
-		if config.SpecialJudge.Mode == 2 {
+		if config.SpecialJudge.Mode >= constants.SpecialJudgeModeInteractive {
 			checkerType = "interactor"
 		}
 		if config.SpecialJudge.UseTestlib {
@@ -78,6 +183,8 @@ func compileWorkCodeFiles(config structs.JudgeConfiguration, libraryDir string)
 				config.ConfigDir,
 				libraryDir,
 				checkerType,
+				"g++",
+				compileTimeout,
 			)
 			if err != nil {
 				return err
@@ -91,6 +198,7 @@ func compileWorkCodeFiles(config structs.JudgeConfiguration, libraryDir string)
 				config.ConfigDir,
 				libraryDir,
 				config.SpecialJudge.CheckerLang,
+				compileTimeout,
 			)
 			if err != nil {
 				fmt.Printf("Error!\n%s", err.Error())
@@ -124,6 +232,11 @@ func CompileProblemWorkDirSourceCodes(c *cli.Context) error {
 	if !s.IsDir() {
 		return errors.Errorf("library root not a directory")
 	}
+	// 和评测共用编译缓存，源码没有变化时不需要重新编译
+	err = provider.SetBuildCache(c.String("build-cache"), c.Int64("build-cache-size")*1024*1024)
+	if err != nil {
+		return err
+	}
 	err = compileWorkCodeFiles(session.JudgeConfig, libDir)
 	return err
 }
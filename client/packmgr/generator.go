@@ -14,21 +14,24 @@ import (
 	"os"
 	"path"
 	"strings"
-	"time"
 )
 
 // 运行测试数据生成
 func runTestCaseGen(session *executor.JudgeSession, tCase *structs.TestCase, withAnswer bool) error {
 	// 如果是generator脚本
 	if tCase.UseGenerator {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), getGeneratorTimeout(&session.JudgeConfig, tCase.Generator))
 		defer cancel()
 		inbytes, err := utils.CallGenerator(ctx, tCase, session.ConfigDir)
 		if err != nil {
 			return err
 		}
 		// 写入到文件
-		err = ioutil.WriteFile(path.Join(session.ConfigDir, tCase.Input), inbytes, 0664)
+		inFile := path.Join(session.ConfigDir, tCase.Input)
+		if err = os.MkdirAll(path.Dir(inFile), 0775); err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(inFile, inbytes, 0664)
 		if err != nil {
 			return err
 		}
@@ -43,7 +46,7 @@ func runTestCaseGen(session *executor.JudgeSession, tCase *structs.TestCase, wit
 		if err != nil {
 			return err
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), getAnswerTimeout(&session.JudgeConfig))
 		defer cancel()
 		rel, err := utils.RunUnixShell(&structs.ShellOptions{
 			Context: ctx,
@@ -151,6 +154,12 @@ func RunTestCaseGenerator(c *cli.Context) error {
 	answerCaseIndex := c.Uint("answer")
 	testCaseIndex := c.Int("case")
 
+	// 展开generator脚本
+	err = applyGeneratorScript(&session.JudgeConfig)
+	if err != nil {
+		return err
+	}
+
 	// 编译答案代码
 	if withAnswer {
 		err = initWork(session, answerCaseIndex)
@@ -160,5 +169,13 @@ func RunTestCaseGenerator(c *cli.Context) error {
 		defer session.Clean()
 	}
 
-	return runTestCaseGenerator(session, testCaseIndex, withAnswer)
+	err = runTestCaseGenerator(session, testCaseIndex, withAnswer)
+	if err != nil {
+		return err
+	}
+	// 脚本会修改test cases，需要保存到配置文件
+	if session.JudgeConfig.TestLib.GeneratorScript != "" {
+		return session.SaveConfiguration(!c.Bool("silence"))
+	}
+	return nil
 }
@@ -0,0 +1,133 @@
+package packmgr
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// 全部分组汇总时使用的分组名
+const validatorOverviewAllGroups = "(all)"
+
+// 单个变量的边界命中统计
+type validatorBoundsStat struct {
+	Tests  int // 出现该变量的测试数据数
+	MinHit int // 取到最小值的测试数据数
+	MaxHit int // 取到最大值的测试数据数
+}
+
+// validator的testOverviewLog汇总 (按group区分)
+type validatorOverview struct {
+	tests    map[string]int                             // group => 测试数据数
+	bounds   map[string]map[string]*validatorBoundsStat // group => variable => stat
+	features map[string]map[string]int                  // group => feature => 命中的测试数据数
+}
+
+func newValidatorOverview() *validatorOverview {
+	return &validatorOverview{
+		tests:    map[string]int{},
+		bounds:   map[string]map[string]*validatorBoundsStat{},
+		features: map[string]map[string]int{},
+	}
+}
+
+// 读取一个overview log文件并汇总
+func (vo *validatorOverview) collect(group, logFile string) error {
+	content, err := ioutil.ReadFile(logFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	bounds, features := utils.ParseTestlibOverviewLog(string(content))
+	vo.add(group, bounds, features)
+	// 存在分组时，额外汇总全部数据
+	if group != "" {
+		vo.add(validatorOverviewAllGroups, bounds, features)
+	}
+	return nil
+}
+
+func (vo *validatorOverview) add(group string, bounds map[string]utils.TestlibBoundsHit, features map[string]bool) {
+	vo.tests[group]++
+	if _, ok := vo.bounds[group]; !ok {
+		vo.bounds[group] = map[string]*validatorBoundsStat{}
+		vo.features[group] = map[string]int{}
+	}
+	for name, hit := range bounds {
+		stat, ok := vo.bounds[group][name]
+		if !ok {
+			stat = &validatorBoundsStat{}
+			vo.bounds[group][name] = stat
+		}
+		stat.Tests++
+		if hit.MinHit {
+			stat.MinHit++
+		}
+		if hit.MaxHit {
+			stat.MaxHit++
+		}
+	}
+	for name, hit := range features {
+		if hit {
+			vo.features[group][name]++
+		} else if _, ok := vo.features[group][name]; !ok {
+			vo.features[group][name] = 0
+		}
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 打印汇总表格，从未取到的边界会被标记出来
+func (vo *validatorOverview) print() {
+	if len(vo.tests) == 0 {
+		return
+	}
+	fmt.Println("\n[validator] bounds overview:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "GROUP\tVARIABLE\tTESTS\tMIN HIT\tMAX HIT\tWARNING")
+	for _, group := range sortedKeys(vo.tests) {
+		groupName := group
+		if groupName == "" {
+			groupName = "-"
+		}
+		names := make([]string, 0, len(vo.bounds[group]))
+		for name := range vo.bounds[group] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			stat := vo.bounds[group][name]
+			warnings := make([]string, 0, 2)
+			if stat.MinHit == 0 {
+				warnings = append(warnings, "min never reached")
+			}
+			if stat.MaxHit == 0 {
+				warnings = append(warnings, "max never reached")
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", groupName, name, stat.Tests, stat.MinHit, stat.MaxHit, strings.Join(warnings, ", "))
+		}
+		for _, name := range sortedKeys(vo.features[group]) {
+			warning := ""
+			if vo.features[group][name] == 0 {
+				warning = "feature never hit"
+			}
+			// 特性只统计命中次数
+			_, _ = fmt.Fprintf(w, "%s\tfeature \"%s\"\t%d/%d\t-\t-\t%s\n", groupName, name, vo.features[group][name], vo.tests[group], warning)
+		}
+	}
+	_ = w.Flush()
+}
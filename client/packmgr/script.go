@@ -0,0 +1,113 @@
+package packmgr
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"github.com/pkg/errors"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// 获取generator的运行时间限制，generator自身的设置优先，其次是题目的timeouts设置
+func getGeneratorTimeout(config *structs.JudgeConfiguration, script string) time.Duration {
+	timeLimit := executor.GetTimeouts(config).Generator
+	name, _, err := utils.ParseGeneratorScript(script)
+	if err == nil {
+		for _, gen := range config.TestLib.Generators {
+			if gen.Name == name && gen.TimeLimit > 0 {
+				timeLimit = gen.TimeLimit
+				break
+			}
+		}
+	}
+	return time.Duration(timeLimit) * time.Millisecond
+}
+
+// 打包工具运行程序时没有设置超时的默认值
+const defaultToolTimeout = 5 * time.Second
+
+// 获取阶段的超时时间，没有设置时使用defaultToolTimeout
+func getToolTimeout(timeout int) time.Duration {
+	if timeout > 0 {
+		return time.Duration(timeout) * time.Millisecond
+	}
+	return defaultToolTimeout
+}
+
+// 获取生成答案时运行答案代码的超时时间
+func getAnswerTimeout(config *structs.JudgeConfiguration) time.Duration {
+	return getToolTimeout(executor.GetTimeouts(config).Run)
+}
+
+// 获取checker的超时时间
+func getCheckerTimeout(config *structs.JudgeConfiguration) time.Duration {
+	return getToolTimeout(executor.GetTimeouts(config).Checker)
+}
+
+// 获取validator的超时时间
+func getValidatorTimeout(config *structs.JudgeConfiguration) time.Duration {
+	return getToolTimeout(executor.GetTimeouts(config).Validator)
+}
+
+// 检查generator是否在配置中定义
+func isGeneratorDefined(config *structs.JudgeConfiguration, name string) bool {
+	for _, gen := range config.TestLib.Generators {
+		if gen.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 解析generator脚本，并将展开的结果写入到test cases (按Handle更新或追加)
+func applyGeneratorScript(config *structs.JudgeConfiguration) error {
+	if config.TestLib.GeneratorScript == "" {
+		return nil
+	}
+	fp, err := os.Open(path.Join(config.ConfigDir, config.TestLib.GeneratorScript))
+	if err != nil {
+		return errors.Errorf("[generator] open generator script error: %s", err.Error())
+	}
+	defer fp.Close()
+	items, err := utils.ParseGeneratorScriptFile(fp)
+	if err != nil {
+		return errors.Errorf("[generator] parse generator script error: %s", err.Error())
+	}
+	handles := map[string]int{}
+	for key, tc := range config.TestCases {
+		handles[tc.Handle] = key
+	}
+	for _, item := range items {
+		if !isGeneratorDefined(config, item.Generator) {
+			return errors.Errorf("[generator] line %d: generator (%s) not defined", item.Line, item.Generator)
+		}
+		handle := strconv.Itoa(item.Index)
+		key, ok := handles[handle]
+		if !ok {
+			config.TestCases = append(config.TestCases, structs.TestCase{
+				Handle:  handle,
+				Order:   item.Index,
+				Name:    fmt.Sprintf("Test #%d", item.Index),
+				Enabled: true,
+			})
+			key = len(config.TestCases) - 1
+			handles[handle] = key
+		}
+		tc := &config.TestCases[key]
+		if tc.Input == "" {
+			tc.Input = fmt.Sprintf("cases/%d.in", item.Index)
+		}
+		if tc.Output == "" {
+			tc.Output = fmt.Sprintf("cases/%d.out", item.Index)
+		}
+		tc.UseGenerator = true
+		tc.Generator = item.Command()
+	}
+	log.Printf("[generator] %d test case(s) expanded from generator script", len(items))
+	return nil
+}
@@ -0,0 +1,265 @@
+package packmgr
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 对拍工作目录 (相对于题目目录)
+const stressWorkDir = "stress"
+
+// 对拍运行环境
+type stressContext struct {
+	reference *executor.JudgeSession // 标准解答 (用于生成答案)
+	candidate *executor.JudgeSession // 待测解答 (使用完整的评测流程)
+	validator string                 // validator程序路径，不存在时为空
+	tCase     structs.TestCase       // 对拍使用的测试数据
+}
+
+// 替换generator脚本中的${seed}，没有的话把种子追加到参数末尾
+func getStressGeneratorScript(script string, seed int) string {
+	if strings.Contains(script, "${seed}") {
+		return strings.Replace(script, "${seed}", strconv.Itoa(seed), -1)
+	}
+	return fmt.Sprintf("%s %d", script, seed)
+}
+
+// 获取标准解答的编号，未指定时优先使用main解答
+func getReferenceAnswerIndex(config *structs.JudgeConfiguration, index int) int {
+	if index >= 0 {
+		return index
+	}
+	for key, acase := range config.AnswerCases {
+		if acase.ExpectedVerdict == constants.AnswerVerdictMain {
+			return key
+		}
+	}
+	return 0
+}
+
+// 创建并编译待测解答的评测会话
+func newStressCandidateSession(c *cli.Context, configFile string) (*executor.JudgeSession, error) {
+	session, err := executor.NewSession(configFile)
+	if err != nil {
+		return nil, err
+	}
+	candidateIndex := c.Int("candidate")
+	if candidateIndex >= 0 {
+		if candidateIndex >= len(session.JudgeConfig.AnswerCases) {
+			return nil, errors.Errorf("[stress] answer case #%d not exists", candidateIndex)
+		}
+		acase := session.JudgeConfig.AnswerCases[candidateIndex]
+		if acase.FileName != "" {
+			session.CodeFile = path.Join(session.ConfigDir, acase.FileName)
+		}
+		session.CodeContent = acase.Content
+		session.CodeLangName = acase.Language
+	} else {
+		if c.Args().Get(1) == "" {
+			return nil, errors.Errorf("[stress] candidate code file or answer case index is required")
+		}
+		session.CodeFile = c.Args().Get(1)
+		session.CodeLangName = c.String("language")
+	}
+	if session.JudgeConfig.SpecialJudge.Mode > 0 {
+		libraryDir, err := filepath.Abs(c.String("library"))
+		if err != nil {
+			return nil, errors.Errorf("[stress] get library root error: %s", err.Error())
+		}
+		session.LibraryDir = libraryDir
+	}
+	session.SessionID = uuid.NewV4().String()
+	session.SessionRoot = "/tmp"
+	sessionDir, err := utils.GetSessionDir(session.SessionRoot, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.SessionDir = sessionDir
+
+	judgeResult := structs.JudgeResult{}
+	err = session.PrepareJudge(&judgeResult)
+	if err != nil {
+		session.Clean()
+		if judgeResult.JudgeResult == constants.JudgeFlagCE {
+			return nil, errors.Errorf("[stress] candidate compile error:\n%s", judgeResult.CeInfo)
+		}
+		return nil, errors.Errorf("[stress] prepare candidate error: %s", err.Error())
+	}
+	return session, nil
+}
+
+// 使用标准解答生成答案，并评测待测解答
+// generate为true时会先运行generator生成输入
+func (sc *stressContext) judge(generate bool) (*structs.TestCaseResult, error) {
+	sc.tCase.UseGenerator = generate
+	err := runTestCaseGen(sc.reference, &sc.tCase, true)
+	if err != nil {
+		return nil, err
+	}
+	return sc.candidate.JudgeTestCase(sc.tCase), nil
+}
+
+// 检查缩减后的输入是否仍然能让待测解答出错
+func (sc *stressContext) stillFailing(input string) (bool, error) {
+	if sc.validator != "" {
+		vCase := structs.TestlibValidatorCase{Input: input, ExpectedVerdict: true}
+		if err := runValidatorCase(&sc.reference.JudgeConfig, sc.validator, &vCase); err != nil {
+			return false, err
+		}
+		if !vCase.ValidatorVerdict {
+			return false, nil
+		}
+	}
+	err := ioutil.WriteFile(path.Join(sc.reference.ConfigDir, sc.tCase.Input), []byte(input), 0664)
+	if err != nil {
+		return false, err
+	}
+	result, err := sc.judge(false)
+	if err != nil {
+		// 标准解答无法处理缩减后的输入，视为无效输入
+		return false, nil
+	}
+	return result.JudgeResult != constants.JudgeFlagAC, nil
+}
+
+// 先按行、再按行内的token缩减输入
+func (sc *stressContext) minimize(input string) (string, error) {
+	lines := strings.Split(strings.TrimRight(input, "\n"), "\n")
+	lines, err := utils.ShrinkByDeltaDebugging(lines, func(units []string) (bool, error) {
+		return sc.stillFailing(strings.Join(units, "\n") + "\n")
+	})
+	if err != nil {
+		return "", err
+	}
+	for i := range lines {
+		tokens := strings.Fields(lines[i])
+		shrunk, err := utils.ShrinkByDeltaDebugging(tokens, func(units []string) (bool, error) {
+			trial := make([]string, len(lines))
+			copy(trial, lines)
+			trial[i] = strings.Join(units, " ")
+			return sc.stillFailing(strings.Join(trial, "\n") + "\n")
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(shrunk) < len(tokens) {
+			lines[i] = strings.Join(shrunk, " ")
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// RunStressTesting 运行对拍 (APP入口)
+func RunStressTesting(c *cli.Context) error {
+	configFile := c.Args().Get(0)
+	_, err := os.Stat(configFile)
+	if err != nil && os.IsNotExist(err) {
+		return errors.Errorf("[stress] problem config file (%s) not found", configFile)
+	}
+	reference, err := executor.NewSession(configFile)
+	if err != nil {
+		return err
+	}
+	config := &reference.JudgeConfig
+
+	script := c.String("generator")
+	name, _, err := utils.ParseGeneratorScript(script)
+	if err != nil {
+		return errors.Errorf("[stress] parse generator script error: %s", err.Error())
+	}
+	if !isGeneratorDefined(config, name) {
+		return errors.Errorf("[stress] generator (%s) not defined", name)
+	}
+	if len(config.AnswerCases) == 0 {
+		return errors.Errorf("[stress] no answer case defined")
+	}
+	referenceIndex := getReferenceAnswerIndex(config, c.Int("reference"))
+	if referenceIndex >= len(config.AnswerCases) {
+		return errors.Errorf("[stress] answer case #%d not exists", referenceIndex)
+	}
+
+	// 编译标准解答
+	err = initWork(reference, uint(referenceIndex))
+	if err != nil {
+		return err
+	}
+	defer reference.Clean()
+	// 编译待测解答
+	candidate, err := newStressCandidateSession(c, configFile)
+	if err != nil {
+		return err
+	}
+	defer candidate.Clean()
+
+	workDir := path.Join(reference.ConfigDir, stressWorkDir)
+	if err = os.MkdirAll(workDir, 0775); err != nil {
+		return err
+	}
+	sc := stressContext{
+		reference: reference,
+		candidate: candidate,
+		tCase: structs.TestCase{
+			Handle: "stress",
+			Input:  path.Join(stressWorkDir, "current.in"),
+			Output: path.Join(stressWorkDir, "current.out"),
+		},
+	}
+	defer os.Remove(path.Join(reference.ConfigDir, sc.tCase.Input))
+	defer os.Remove(path.Join(reference.ConfigDir, sc.tCase.Output))
+	if err := isValidatorExists(config); err == nil {
+		sc.validator, _ = utils.GetCompiledBinaryFileAbsPath("validator", config.TestLib.ValidatorName, config.ConfigDir)
+	}
+
+	times := c.Int("times")
+	seed := c.Int("seed")
+	for i := 0; i < times; i, seed = i+1, seed+1 {
+		sc.tCase.Generator = getStressGeneratorScript(script, seed)
+		result, err := sc.judge(true)
+		if err != nil {
+			return err
+		}
+		flagName := getShortFlagName(result.JudgeResult)
+		log.Printf("[stress] #%d (seed %d): %s", i+1, seed, flagName)
+		if result.JudgeResult == constants.JudgeFlagAC {
+			continue
+		}
+		// 保存出错的输入
+		inbytes, err := ioutil.ReadFile(path.Join(reference.ConfigDir, sc.tCase.Input))
+		if err != nil {
+			return err
+		}
+		failedFile := path.Join(workDir, fmt.Sprintf("failed_%d.in", seed))
+		if err = ioutil.WriteFile(failedFile, inbytes, 0664); err != nil {
+			return err
+		}
+		log.Printf("[stress] counterexample saved to %s", failedFile)
+		if c.Bool("minimize") {
+			log.Printf("[stress] minimizing counterexample...")
+			minimized, err := sc.minimize(string(inbytes))
+			if err != nil {
+				return err
+			}
+			minimizedFile := path.Join(workDir, fmt.Sprintf("failed_%d.min.in", seed))
+			if err = ioutil.WriteFile(minimizedFile, []byte(minimized), 0664); err != nil {
+				return err
+			}
+			log.Printf("[stress] minimized counterexample (%d -> %d bytes) saved to %s", len(inbytes), len(minimized), minimizedFile)
+		}
+		return errors.Errorf("[stress] candidate got %s on generator '%s'", flagName, sc.tCase.Generator)
+	}
+	log.Printf("[stress] all %d tests passed", times)
+	return nil
+}
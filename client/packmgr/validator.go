@@ -6,17 +6,18 @@ import (
 	"github.com/LanceLRQ/deer-executor/v2/common/utils"
 	"github.com/LanceLRQ/deer-executor/v2/executor"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/urfave/cli/v2"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
-	"time"
 )
 
-func runValidatorCase(vBin string, vCase *structs.TestlibValidatorCase) error {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+func runValidatorCase(config *structs.JudgeConfiguration, vBin string, vCase *structs.TestlibValidatorCase) error {
+	ctx, cancel := context.WithTimeout(context.Background(), getValidatorTimeout(config))
+	defer cancel()
 	rel, err := utils.RunUnixShell(&structs.ShellOptions{
 		Context:   ctx,
 		Name:      vBin,
@@ -42,13 +43,20 @@ func runValidatorCase(vBin string, vCase *structs.TestlibValidatorCase) error {
 	return nil
 }
 
-func runTestCase(configDir, vBin string, tCase *structs.TestCase) error {
-	ctx, _ := context.WithTimeout(context.Background(), 3*time.Second)
+// validator是否为testlib(c++)程序，只有testlib才支持--group等参数
+func isTestlibValidator(config *structs.JudgeConfiguration) bool {
+	return getTestlibCppCompiler(getTestlibLanguage(config.TestLib.ValidatorLanguage, config.TestLib.Validator)) != nil
+}
+
+func runTestCase(config *structs.JudgeConfiguration, vBin string, tCase *structs.TestCase, overview *validatorOverview) error {
+	configDir := config.ConfigDir
 	var inbytes []byte
 	var err error
 	// 判断是generator还是普通input
 	if tCase.UseGenerator {
-		inbytes, err = utils.CallGenerator(ctx, tCase, configDir)
+		gctx, gcancel := context.WithTimeout(context.Background(), getGeneratorTimeout(config, tCase.Generator))
+		defer gcancel()
+		inbytes, err = utils.CallGenerator(gctx, tCase, configDir)
 		if err != nil {
 			return err
 		}
@@ -59,10 +67,24 @@ func runTestCase(configDir, vBin string, tCase *structs.TestCase) error {
 		}
 	}
 
+	// testlib validator支持输出边界命中情况，并按group校验
+	var args []string
+	var overviewLog string
+	if overview != nil && isTestlibValidator(config) {
+		overviewLog = path.Join(os.TempDir(), uuid.NewV4().String()+".log")
+		defer os.Remove(overviewLog)
+		args = append(args, "--testOverviewLogFileName", overviewLog)
+		if tCase.Group != "" {
+			args = append(args, "--group", tCase.Group)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), getValidatorTimeout(config))
+	defer cancel()
 	rel, err := utils.RunUnixShell(&structs.ShellOptions{
 		Context:   ctx,
 		Name:      vBin,
-		Args:      nil,
+		Args:      args,
 		StdWriter: nil,
 		OnStart: func(writer io.Writer) error {
 			_, err := writer.Write(inbytes)
@@ -72,6 +94,11 @@ func runTestCase(configDir, vBin string, tCase *structs.TestCase) error {
 	if err != nil {
 		return err
 	}
+	if overviewLog != "" {
+		if err = overview.collect(tCase.Group, overviewLog); err != nil {
+			return err
+		}
+	}
 	if rel.Success {
 		tCase.ValidatorVerdict = true
 		tCase.ValidatorComment = ""
@@ -128,14 +155,14 @@ func RunTestlibValidatorCases(config *structs.JudgeConfiguration, caseIndex int)
 	if caseIndex < 0 {
 		for key := range config.TestLib.ValidatorCases {
 			log.Printf("[validator] run validator case #%d", key)
-			err := runValidatorCase(validator, &config.TestLib.ValidatorCases[key])
+			err := runValidatorCase(config, validator, &config.TestLib.ValidatorCases[key])
 			if err != nil {
 				return err
 			}
 		}
 	} else {
 		log.Printf("[validator] run validator case #%d", caseIndex)
-		err := runValidatorCase(validator, &config.TestLib.ValidatorCases[caseIndex])
+		err := runValidatorCase(config, validator, &config.TestLib.ValidatorCases[caseIndex])
 		if err != nil {
 			return err
 		}
@@ -150,22 +177,24 @@ func RunTestCasesInputValidation(config *structs.JudgeConfiguration, caseIndex i
 	if err != nil {
 		return err
 	}
+	overview := newValidatorOverview()
 	// 执行遍历
 	if caseIndex < 0 {
 		for key := range config.TestCases {
 			log.Printf("[validator] run test case #%d", key)
-			err := runTestCase(config.ConfigDir, validator, &config.TestCases[key])
+			err := runTestCase(config, validator, &config.TestCases[key], overview)
 			if err != nil {
 				return err
 			}
 		}
 	} else {
 		log.Printf("[validator] run test case #%d", caseIndex)
-		err := runTestCase(config.ConfigDir, validator, &config.TestCases[caseIndex])
+		err := runTestCase(config, validator, &config.TestCases[caseIndex], overview)
 		if err != nil {
 			return err
 		}
 	}
+	overview.print()
 	return nil
 }
 
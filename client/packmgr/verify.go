@@ -0,0 +1,212 @@
+package packmgr
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/urfave/cli/v2"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// 单个解答的验证结果
+type answerVerifyResult struct {
+	Name     string              // 解答名称
+	Expected string              // 期望的判定
+	Result   structs.JudgeResult // 评测结果
+	Matched  bool                // 是否符合期望
+}
+
+// 获取解答的显示名称
+func getAnswerCaseName(acase *structs.AnswerCase, index int) string {
+	if acase.Name != "" {
+		return acase.Name
+	}
+	if acase.FileName != "" {
+		return path.Base(acase.FileName)
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+// 获取评测结果的简称
+func getShortFlagName(flag int) string {
+	name, ok := constants.FlagShortNameMap[flag]
+	if !ok {
+		return "?"
+	}
+	return name
+}
+
+// 检查解答的期望判定设置
+func checkAnswerCasesVerdict(config *structs.JudgeConfiguration) error {
+	mainCount := 0
+	for key, acase := range config.AnswerCases {
+		if _, err := utils.CheckAnswerVerdict(acase.ExpectedVerdict, nil); err != nil {
+			return errors.Errorf("[verify] answer case #%d: %s", key, err.Error())
+		}
+		if acase.ExpectedVerdict == constants.AnswerVerdictMain {
+			mainCount++
+		}
+	}
+	if mainCount > 1 {
+		return errors.Errorf("[verify] only one answer case can be marked as 'main'")
+	}
+	return nil
+}
+
+// 使用完整的评测流程运行一个解答
+func runAnswerCaseJudge(configFile, libraryDir string, answerCaseIndex int) (*answerVerifyResult, error) {
+	session, err := executor.NewSession(configFile)
+	if err != nil {
+		return nil, err
+	}
+	acase := session.JudgeConfig.AnswerCases[answerCaseIndex]
+	if session.JudgeConfig.SpecialJudge.Mode > 0 {
+		session.LibraryDir = libraryDir
+	}
+	// 强制设定工作目录
+	session.SessionID = uuid.NewV4().String()
+	session.SessionRoot = "/tmp"
+	sessionDir, err := utils.GetSessionDir(session.SessionRoot, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.SessionDir = sessionDir
+	defer session.Clean()
+
+	if acase.FileName != "" {
+		session.CodeFile = path.Join(session.ConfigDir, acase.FileName)
+	}
+	session.CodeContent = acase.Content
+	session.CodeLangName = acase.Language
+	// 需要拿到每一组测试数据的结果
+	session.RunAllCases = true
+
+	result := session.RunJudge()
+	flags := make([]int, 0, len(result.TestCases))
+	for _, tc := range result.TestCases {
+		flags = append(flags, tc.JudgeResult)
+	}
+	matched, err := utils.CheckAnswerVerdict(acase.ExpectedVerdict, flags)
+	if err != nil {
+		return nil, err
+	}
+	// 评测被中断时(如灾难性错误)，结果是不完整的
+	if len(flags) < len(session.JudgeConfig.TestCases) {
+		matched = false
+	}
+	expected := acase.ExpectedVerdict
+	if expected == "" {
+		expected = constants.AnswerVerdictAC
+	}
+	return &answerVerifyResult{
+		Name:     getAnswerCaseName(&acase, answerCaseIndex),
+		Expected: expected,
+		Result:   result,
+		Matched:  matched,
+	}, nil
+}
+
+// 打印验证结果矩阵 (行为测试数据，列为解答)
+func printAnswerVerifyMatrix(config *structs.JudgeConfiguration, results []*answerVerifyResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	header := []string{"TEST"}
+	for _, rel := range results {
+		header = append(header, fmt.Sprintf("%s (%s)", rel.Name, rel.Expected))
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for key, tc := range config.TestCases {
+		name := tc.Handle
+		if name == "" {
+			name = fmt.Sprintf("%d", key)
+		}
+		row := []string{name}
+		for _, rel := range results {
+			cell := "-"
+			if key < len(rel.Result.TestCases) {
+				tcResult := rel.Result.TestCases[key]
+				cell = fmt.Sprintf("%s %dms", getShortFlagName(tcResult.JudgeResult), tcResult.TimeUsed)
+			}
+			row = append(row, cell)
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	row := []string{"RESULT"}
+	for _, rel := range results {
+		verdict := "OK"
+		if !rel.Matched {
+			verdict = "FAILED"
+		}
+		row = append(row, fmt.Sprintf("%s %s", getShortFlagName(rel.Result.JudgeResult), verdict))
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	_ = w.Flush()
+}
+
+// VerifyAnswerCases 验证全部解答的判定是否符合期望 (APP入口)
+func VerifyAnswerCases(c *cli.Context) error {
+	configFile := c.Args().Get(0)
+	_, err := os.Stat(configFile)
+	if err != nil && os.IsNotExist(err) {
+		return errors.Errorf("[verify] problem config file (%s) not found", configFile)
+	}
+	session, err := executor.NewSession(configFile)
+	if err != nil {
+		return err
+	}
+	config := &session.JudgeConfig
+	if len(config.AnswerCases) == 0 {
+		return errors.Errorf("[verify] no answer case defined")
+	}
+	err = checkAnswerCasesVerdict(config)
+	if err != nil {
+		return err
+	}
+	libraryDir, err := filepath.Abs(c.String("library"))
+	if err != nil {
+		return errors.Errorf("[verify] get library root error: %s", err.Error())
+	}
+
+	answerCaseIndex := c.Int("answer")
+	if answerCaseIndex >= len(config.AnswerCases) {
+		return errors.Errorf("[verify] answer case #%d not exists", answerCaseIndex)
+	}
+	indexes := make([]int, 0, len(config.AnswerCases))
+	if answerCaseIndex < 0 {
+		for key := range config.AnswerCases {
+			indexes = append(indexes, key)
+		}
+	} else {
+		indexes = append(indexes, answerCaseIndex)
+	}
+
+	results := make([]*answerVerifyResult, 0, len(indexes))
+	failed := make([]string, 0)
+	for _, key := range indexes {
+		log.Printf("[verify] run answer case #%d", key)
+		rel, err := runAnswerCaseJudge(configFile, libraryDir, key)
+		if err != nil {
+			return err
+		}
+		if rel.Result.JudgeResult == constants.JudgeFlagCE {
+			log.Printf("[verify] answer case #%d compile error:\n%s", key, rel.Result.CeInfo)
+		}
+		results = append(results, rel)
+		if !rel.Matched {
+			failed = append(failed, rel.Name)
+		}
+	}
+	printAnswerVerifyMatrix(config, results)
+	if len(failed) > 0 {
+		return errors.Errorf("[verify] solution(s) didn't behave as declared: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -13,14 +13,14 @@ var AppProblemSubCommands = cli.Commands{
 		Aliases:   []string{"b"},
 		Usage:     "compile binary source codes",
 		ArgsUsage: "<configs_file>",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:    "library",
 				Aliases: []string{"l"},
 				Value:   "./lib",
 				Usage:   "library root for special judge, contains \"testlib.h\" and \"bits/stdc++.h\" etc.",
 			},
-		},
+		}, BuildCacheFlags()...),
 		Action: packmgr.CompileProblemWorkDirSourceCodes,
 	},
 	{
@@ -110,4 +110,78 @@ var AppProblemSubCommands = cli.Commands{
 		},
 		Action: packmgr.RunCheckerCases,
 	},
+	{
+		Name:      "verify",
+		HelpName:  "deer-executor problem verify",
+		Usage:     "judge answer cases and check their expected verdicts",
+		ArgsUsage: "<configs_file>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "library",
+				Aliases: []string{"l"},
+				Value:   "./lib",
+				Usage:   "library root for special judge, contains \"testlib.h\" and \"bits/stdc++.h\" etc.",
+			},
+			&cli.IntFlag{
+				Name:    "answer",
+				Aliases: []string{"a"},
+				Value:   -1,
+				Usage:   "answer case index, -1 means all.",
+			},
+		},
+		Action: packmgr.VerifyAnswerCases,
+	},
+	{
+		Name:      "stress",
+		HelpName:  "deer-executor problem stress",
+		Usage:     "stress testing: compare a candidate solution with the reference answer on generated inputs",
+		ArgsUsage: "<configs_file> [candidate_code_file]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "generator",
+				Aliases:  []string{"g"},
+				Required: true,
+				Usage:    "generator script, e.g. \"gen 10 100\". '${seed}' will be replaced by the seed, or the seed will be appended",
+			},
+			&cli.IntFlag{
+				Name:    "reference",
+				Aliases: []string{"r"},
+				Value:   -1,
+				Usage:   "reference answer case index, -1 means the 'main' answer case (or the first one)",
+			},
+			&cli.IntFlag{
+				Name:  "candidate",
+				Value: -1,
+				Usage: "candidate answer case index, -1 means using the candidate code file",
+			},
+			&cli.StringFlag{
+				Name:  "language",
+				Value: "auto",
+				Usage: "candidate code language",
+			},
+			&cli.IntFlag{
+				Name:    "times",
+				Aliases: []string{"n"},
+				Value:   100,
+				Usage:   "max test times",
+			},
+			&cli.IntFlag{
+				Name:  "seed",
+				Value: 1,
+				Usage: "start seed",
+			},
+			&cli.BoolFlag{
+				Name:    "minimize",
+				Aliases: []string{"m"},
+				Usage:   "minimize the counterexample by delta-debugging",
+			},
+			&cli.StringFlag{
+				Name:    "library",
+				Aliases: []string{"l"},
+				Value:   "./lib",
+				Usage:   "library root for special judge, contains \"testlib.h\" and \"bits/stdc++.h\" etc.",
+			},
+		},
+		Action: packmgr.RunStressTesting,
+	},
 }
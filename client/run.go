@@ -5,7 +5,7 @@ import (
 )
 
 // RunFlags for cli command 'run'
-var RunFlags = []cli.Flag{
+var RunFlags = append([]cli.Flag{
 	&cli.BoolFlag{
 		Name:  "no-clean",
 		Value: false,
@@ -15,7 +15,7 @@ var RunFlags = []cli.Flag{
 		Name:    "language",
 		Aliases: []string{"l"},
 		Value:   "auto",
-		Usage:   "Code language name",
+		Usage:   "Code language name or variant (e.g. g++17, pypy3), see 'languages' command",
 	},
 	&cli.BoolFlag{
 		Name:  "debug",
@@ -27,6 +27,16 @@ var RunFlags = []cli.Flag{
 		Value: 0,
 		Usage: "Start benchmark",
 	},
+	&cli.BoolFlag{
+		Name:  "stream-compare",
+		Value: false,
+		Usage: "Compare output with the answer while running, and stop the program at a definite WA or OLE",
+	},
+	&cli.BoolFlag{
+		Name:  "zygote",
+		Value: false,
+		Usage: "Start the interpreter once per session and fork a warm worker for each test case (python), or build a startup cache first (java, kotlin)",
+	},
 	&cli.StringFlag{
 		Name:    "persistence",
 		Aliases: []string{"p"},
@@ -38,6 +48,11 @@ var RunFlags = []cli.Flag{
 		Value: false,
 		Usage: "Persistent an ACCEPTED test case's output data, will increase the file size",
 	},
+	&cli.BoolFlag{
+		Name:  "save-source",
+		Value: false,
+		Usage: "Persistent the submitted code file, used by 'similarity' command",
+	},
 	&cli.StringFlag{
 		Name:  "compress",
 		Value: "gzip",
@@ -97,4 +112,20 @@ var RunFlags = []cli.Flag{
 		Value: false,
 		Usage: "output logs to stdout",
 	},
+}, BuildCacheFlags()...)
+
+// BuildCacheFlags 编译缓存的参数 (run和problem build共用)
+func BuildCacheFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "build-cache",
+			Value: "",
+			Usage: "build cache dir for compiled programs, must be private to the current user, default is '<tmp>/deer-build-cache-<uid>'",
+		},
+		&cli.Int64Flag{
+			Name:  "build-cache-size",
+			Value: 512,
+			Usage: "build cache size limit (MB), least recently used programs will be removed. 0 means disable the build cache",
+		},
+	}
 }
@@ -16,38 +16,20 @@ import (
 
 // JudgementBenchmark judgement benchmark args
 type JudgementBenchmark struct {
-	TimeUsed float64        `json:"time_used"`
-	Counter  map[string]int `json:"counter"`
-	Message  string         `json:"message"`
+	TimeUsed     float64        `json:"time_used"`
+	Counter      map[string]int `json:"counter"`
+	Message      string         `json:"message"`
+	StartupTime  float64        `json:"startup_time"`   // Total startup time of warm workers (ms, zygote mode)
+	SolutionTime float64        `json:"solution_time"`  // Total time used by solutions of all test cases (ms)
+	ColdTimeUsed float64        `json:"cold_time_used"` // Total time used with cold start (zygote mode)
+	SpeedUp      float64        `json:"speed_up"`       // cold_time_used / time_used (zygote mode)
 }
 
-func runJudgeBenchmark(c *cli.Context, configFile string) error {
+// 连续评测times次，并统计评测结果和耗时
+func benchmarkJudge(rOptions *JudgementRunOption, times int, rfp *os.File) *JudgementBenchmark {
 	rel := JudgementBenchmark{
 		Counter: map[string]int{},
 	}
-
-	times := c.Int("benchmark")
-	rfp, err := os.OpenFile("./report.log", os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer rfp.Close()
-
-	workDir := c.String("work-dir")
-	// 构建运行选项
-	rOptions := &JudgementRunOption{
-		Clean:       true,
-		ShowLog:     false,
-		LogLevel:    0,
-		WorkDir:     workDir,
-		ConfigFile:  configFile,
-		Language:    c.String("language"),
-		LibraryDir:  c.String("library"),
-		CodePath:    c.Args().Get(1),
-		SessionID:   "",
-		SessionRoot: "",
-	}
-
 	startTime := time.Now().UnixNano()
 	exitCounter := map[int]int{}
 	for i := 0; i < times; i++ {
@@ -70,6 +52,10 @@ func runJudgeBenchmark(c *cli.Context, configFile string) error {
 			_, _ = rfp.WriteString(utils.ObjectToJSONStringFormatted(judgeResult) + "\n")
 		}
 		exitCounter[judgeResult.JudgeResult]++
+		rel.StartupTime += float64(judgeResult.StartupTime)
+		for _, tc := range judgeResult.TestCases {
+			rel.SolutionTime += float64(tc.TimeUsed)
+		}
 	}
 	endTime := time.Now().UnixNano()
 	for key, value := range exitCounter {
@@ -81,8 +67,50 @@ func runJudgeBenchmark(c *cli.Context, configFile string) error {
 		log.Printf("%s: %d\n", name, value)
 	}
 	rel.TimeUsed = float64(endTime - startTime)
-	duration := rel.TimeUsed / float64(time.Second)
-	log.Printf("total time used: %.2fs\n", duration)
+	log.Printf("total time used: %.2fs\n", rel.TimeUsed/float64(time.Second))
+	log.Printf("startup time: %.0fms, solution time: %.0fms\n", rel.StartupTime, rel.SolutionTime)
+	return &rel
+}
+
+func runJudgeBenchmark(c *cli.Context, configFile string) error {
+	times := c.Int("benchmark")
+	rfp, err := os.OpenFile("./report.log", os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer rfp.Close()
+
+	workDir := c.String("work-dir")
+	// 构建运行选项
+	rOptions := &JudgementRunOption{
+		Clean:         true,
+		ShowLog:       false,
+		LogLevel:      0,
+		WorkDir:       workDir,
+		ConfigFile:    configFile,
+		Language:      c.String("language"),
+		LibraryDir:    c.String("library"),
+		CodePath:      c.Args().Get(1),
+		SessionID:     "",
+		SessionRoot:   "",
+		StreamCompare: c.Bool("stream-compare"),
+	}
+
+	if !c.Bool("zygote") {
+		client.NewClientSuccessMessage(benchmarkJudge(rOptions, times, rfp)).Print(true)
+		return nil
+	}
+	// 预热模式下先用冷启动的方式运行一遍作为对比
+	log.Println("[benchmark] cold start")
+	cold := benchmarkJudge(rOptions, times, rfp)
+	log.Println("[benchmark] zygote mode")
+	rOptions.Zygote = true
+	rel := benchmarkJudge(rOptions, times, rfp)
+	rel.ColdTimeUsed = cold.TimeUsed
+	if rel.TimeUsed > 0 {
+		rel.SpeedUp = cold.TimeUsed / rel.TimeUsed
+	}
+	log.Printf("speed-up: %.2fx\n", rel.SpeedUp)
 
 	client.NewClientSuccessMessage(rel).Print(true)
 	return nil
@@ -53,6 +53,10 @@ func runOnceJudge(options *JudgementRunOption) (*commonStructs.JudgeResult, *exe
 	session.CodeFile = options.CodePath
 	session.SessionID = options.SessionID
 	session.SessionRoot = options.SessionRoot
+	session.Zygote = options.Zygote
+	if options.StreamCompare {
+		session.JudgeConfig.StreamCompare = true
+	}
 	// create session info
 	if session.SessionID == "" {
 		session.SessionID = uuid.NewV1().String()
@@ -84,6 +88,7 @@ func runUserJudge(c *cli.Context, configFile, workDir string) (*commonStructs.Ju
 	jOption := persistence.JudgeResultPersisOptions{
 		CompressorType:   compressorType,
 		SaveAcceptedData: c.Bool("save-ac-data"),
+		SaveSource:       c.Bool("save-source"),
 	}
 	jOption.OutFile = c.String("persistence")
 	// 是否要持久化结果
@@ -120,16 +125,18 @@ func runUserJudge(c *cli.Context, configFile, workDir string) (*commonStructs.Ju
 
 	// 构建运行选项
 	rOptions := &JudgementRunOption{
-		Clean:       !c.Bool("no-clean"),
-		ShowLog:     showLog,
-		LogLevel:    logLevel,
-		WorkDir:     workDir,
-		ConfigFile:  configFile,
-		Language:    c.String("language"),
-		LibraryDir:  c.String("library"),
-		CodePath:    c.Args().Get(1),
-		SessionID:   c.String("session-id"),
-		SessionRoot: c.String("session-root"),
+		Clean:         !c.Bool("no-clean"),
+		ShowLog:       showLog,
+		LogLevel:      logLevel,
+		WorkDir:       workDir,
+		ConfigFile:    configFile,
+		Language:      c.String("language"),
+		LibraryDir:    c.String("library"),
+		CodePath:      c.Args().Get(1),
+		SessionID:     c.String("session-id"),
+		SessionRoot:   c.String("session-root"),
+		Zygote:        c.Bool("zygote"),
+		StreamCompare: c.Bool("stream-compare"),
 	}
 
 	if persistenceOn {
@@ -0,0 +1,41 @@
+package run
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/urfave/cli/v2"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// ListLanguages 列出已注册的语言，可选探测编译器/解释器的版本
+func ListLanguages(c *cli.Context) error {
+	probe := c.Bool("probe")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	header := "NAME\tFAMILY\tALIASES\tEXTENSIONS\tREAL-TIME\tJIT MEMORY"
+	if probe {
+		header += "\tVERSION"
+	}
+	_, _ = fmt.Fprintln(w, header)
+	for _, lang := range provider.RegisteredLanguages() {
+		row := fmt.Sprintf(
+			"%s\t%s\t%s\t%s\t%v\t%d",
+			lang.Name,
+			provider.GetLanguageFamily(lang.Name),
+			strings.Join(lang.Aliases, ","),
+			strings.Join(lang.Extensions, ","),
+			lang.RealTime,
+			provider.GetJITMemory(lang.Name),
+		)
+		if probe {
+			version, err := lang.Version()
+			if err != nil {
+				version = "(not available)"
+			}
+			row += "\t" + version
+		}
+		_, _ = fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
@@ -4,6 +4,7 @@ package run
 
 import (
 	"github.com/LanceLRQ/deer-executor/v2/client"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	"os"
@@ -28,7 +29,7 @@ func UserRunJudge(c *cli.Context) error {
 		return err
 	}
 
-	err = loadSystemConfiguration()
+	err = provider.SetBuildCache(c.String("build-cache"), c.Int64("build-cache-size")*1024*1024)
 	if err != nil {
 		client.NewClientErrorMessage(err, nil).Print(true)
 		return err
@@ -20,6 +20,7 @@ func StartJudgement(options *JudgementRunOption) (*executor.JudgeSession, *commo
 	// persistence
 	if options.Persistence != nil {
 		options.Persistence.SessionDir = judgeSession.SessionDir
+		options.Persistence.CodeFile = judgeSession.CodeFile
 		err = result.PersistentJudgeResult(judgeResult, options.Persistence)
 		if err != nil {
 			return nil, nil, err
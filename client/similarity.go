@@ -0,0 +1,228 @@
+package client
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/persistence/result"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/similarity"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SimilarityFlags for cli command 'similarity'
+var SimilarityFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "language",
+		Aliases: []string{"l"},
+		Value:   "auto",
+		Usage:   "Code language of all submissions, 'auto' means detect by file extension and content",
+	},
+	&cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Value:   "",
+		Usage:   "Write JSON report to file (print to stdout if not set)",
+	},
+	&cli.StringFlag{
+		Name:  "html",
+		Value: "",
+		Usage: "Write HTML report to file",
+	},
+	&cli.Float64Flag{
+		Name:    "threshold",
+		Aliases: []string{"t"},
+		Value:   similarity.DefaultThreshold,
+		Usage:   "Minimum similarity (0~1) of reported pairs",
+	},
+	&cli.IntFlag{
+		Name:  "kgram",
+		Value: similarity.DefaultKGram,
+		Usage: "Tokens per fingerprint, shorter matches are ignored",
+	},
+	&cli.IntFlag{
+		Name:  "window",
+		Value: similarity.DefaultWindow,
+		Usage: "Winnowing window size",
+	},
+	&cli.StringSliceFlag{
+		Name:    "base",
+		Aliases: []string{"b"},
+		Usage:   "Template code given to everyone, its fingerprints are ignored (can be set multiple times)",
+	},
+}
+
+// 识别代码的语言
+func matchSimilarityLanguage(keyword string, fileName string, code string) (*provider.LanguageDefinition, error) {
+	if keyword != "auto" && keyword != "" {
+		return provider.MatchLanguage(keyword, "")
+	}
+	lang, _, err := provider.MatchLanguageWithCode("auto", fileName, code)
+	return lang, err
+}
+
+// 读取多文件提交(目录)中对应语言的全部源码，按文件名排序后拼接
+func readProjectSources(projectDir string, keyword string) (*provider.LanguageDefinition, string, error) {
+	var lang *provider.LanguageDefinition
+	var err error
+	if keyword != "auto" && keyword != "" {
+		lang, err = provider.MatchLanguage(keyword, "")
+	} else {
+		lang, err = provider.DetectProjectLanguage(projectDir)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	family := provider.GetLanguageFamily(lang.Name)
+	files := make([]string, 0)
+	err = filepath.Walk(projectDir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			if fileLang, err := provider.MatchLanguage("auto", fpath); err == nil && provider.GetLanguageFamily(fileLang.Name) == family {
+				files = append(files, fpath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(files)
+	codes := make([]string, 0, len(files))
+	for _, file := range files {
+		code, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, "", err
+		}
+		codes = append(codes, string(code))
+	}
+	return lang, strings.Join(codes, "\n"), nil
+}
+
+// 读取评测结果包中保存的代码 (评测时需要使用--save-source)
+func readResultSources(resultFile string, keyword string) (*provider.LanguageDefinition, string, error) {
+	_, sources, err := result.ReadJudgeResultSources(resultFile)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(sources) == 0 {
+		return nil, "", errors.Errorf("no source code in result package, judge with '--save-source' to save it")
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	codes := make([]string, 0, len(names))
+	for _, name := range names {
+		codes = append(codes, string(sources[name]))
+	}
+	code := strings.Join(codes, "\n")
+	lang, err := matchSimilarityLanguage(keyword, names[0], code)
+	return lang, code, err
+}
+
+// 读取一个提交：源码文件、评测结果包或多文件提交的目录
+func loadSimilaritySubmission(fpath string, keyword string, options similarity.Options) (*similarity.Submission, error) {
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return nil, err
+	}
+	var lang *provider.LanguageDefinition
+	var code string
+	switch {
+	case info.IsDir():
+		lang, code, err = readProjectSources(fpath, keyword)
+	case result.IsJudgeResultFile(fpath):
+		lang, code, err = readResultSources(fpath, keyword)
+	default:
+		var content []byte
+		if content, err = ioutil.ReadFile(fpath); err == nil {
+			code = string(content)
+			lang, err = matchSimilarityLanguage(keyword, fpath, code)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return similarity.NewSubmission(path.Base(fpath), lang.Name, code, options), nil
+}
+
+// RunSimilarity 检测提交之间的相似度 (完全离线运行)
+// 参数可以是若干个源码文件、评测结果包，或者包含它们的目录 (目录中的子目录作为一个多文件提交)
+func RunSimilarity(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return errors.Errorf("submission directory or files required")
+	}
+	options := similarity.Options{
+		KGram:     c.Int("kgram"),
+		Window:    c.Int("window"),
+		Threshold: c.Float64("threshold"),
+	}
+	keyword := c.String("language")
+
+	paths := make([]string, 0)
+	for _, arg := range c.Args().Slice() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := ioutil.ReadDir(arg)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), ".") {
+				paths = append(paths, path.Join(arg, entry.Name()))
+			}
+		}
+	}
+	submissions := make([]*similarity.Submission, 0, len(paths))
+	for _, fpath := range paths {
+		sub, err := loadSimilaritySubmission(fpath, keyword, options)
+		if err != nil {
+			log.Printf("[similarity] skip %s: %s\n", fpath, err.Error())
+			continue
+		}
+		submissions = append(submissions, sub)
+	}
+	bases := make([]*similarity.Submission, 0)
+	for _, fpath := range c.StringSlice("base") {
+		sub, err := loadSimilaritySubmission(fpath, keyword, options)
+		if err != nil {
+			return errors.Errorf("load base code (%s) error: %s", fpath, err.Error())
+		}
+		bases = append(bases, sub)
+	}
+	log.Printf("[similarity] compare %d submissions\n", len(submissions))
+
+	report := similarity.Detect(submissions, bases, options)
+	if c.String("html") != "" {
+		fout, err := os.Create(c.String("html"))
+		if err != nil {
+			return err
+		}
+		defer fout.Close()
+		if err = report.WriteHTML(fout); err != nil {
+			return err
+		}
+	}
+	if c.String("output") != "" {
+		return ioutil.WriteFile(c.String("output"), []byte(utils.ObjectToJSONStringFormatted(report)), 0644)
+	}
+	fmt.Println(utils.ObjectToJSONStringFormatted(report))
+	return nil
+}
@@ -35,13 +35,52 @@ const (
 	JudgeFlagSpecialJudgeError = 11
 	// Special Judge Checker Finish, Need Standard Checkup
 	JudgeFlagSpecialJudgeRequireChecker = 12
+	// Forbidden Code (rejected by the lint rules before compiling)
+	JudgeFlagFC = 13
+)
+
+// Answer case expected verdicts
+const (
+	// Main correct solution
+	AnswerVerdictMain = "main"
+	// Accepted on all test cases
+	AnswerVerdictAC = "ac"
+	// Wrong answer on at least one test case
+	AnswerVerdictWA = "wa"
+	// Time limit exceeded on at least one test case
+	AnswerVerdictTLE = "tle"
+	// Time limit exceeded or accepted on every test case
+	AnswerVerdictTLEOrAC = "tle_or_ac"
+	// Runtime error on at least one test case
+	AnswerVerdictRE = "re"
+)
+
+// Problem Types
+const (
+	// Normal problem
+	ProblemTypeNormal = 0
+	// Code-fill problem, the submission is a JSON of answers per blank handle
+	ProblemTypeCodeFill = 1
+	// Unit-test problem, the submission is compiled with a test harness and judged by its report
+	ProblemTypeUnitTest = 2
+	// Output-only problem, the submission is an archive (or directory) of <handle>.out files
+	ProblemTypeOutputOnly = 3
+	// SQL problem, the submission is a query run against a SQLite database built by each test case's input script
+	ProblemTypeSQL = 4
+)
+
+// Unit test report formats
+const (
+	UnitTestReportJUnit = "junit"
+	UnitTestReportJSON  = "json"
 )
 
 // Special Judge Mode
 const (
-	SpecialJudgeModeDisabled    = 0
-	SpecialJudgeModeChecker     = 1
-	SpecialJudgeModeInteractive = 2
+	SpecialJudgeModeDisabled      = 0
+	SpecialJudgeModeChecker       = 1
+	SpecialJudgeModeInteractive   = 2
+	SpecialJudgeModeCommunication = 3
 
 	// unit: ms
 	SpecialJudgeTimeLimit = 1 * 1000
@@ -99,22 +138,30 @@ var FlagMeansMap = map[int]string{
 	9:  "Special Judge Checker Time OUT",
 	10: "Special Judge Checker ERROR",
 	11: "Special Judge Checker Finish, Need Standard Checkup",
+	13: "Forbidden Code",
 }
 
-// MemorySizeForJIT 给动态语言、带虚拟机的语言设定虚拟机自身的初始内存大小
-var MemorySizeForJIT = map[string]int{
-	"gcc":     0,
-	"g++":     0,
-	"java":    393216, // java
-	"python2": 65536,  // py2
-	"python3": 65536,  // py3
-	"nodejs":  262144, // js
-	"golang":  0,
-	"php":     131072, // php
-	"ruby":    65536,  // ruby
-	"rust":    0,
+// FlagShortNameMap map judge flags to short text
+var FlagShortNameMap = map[int]string{
+	0:  "AC",
+	1:  "PE",
+	2:  "TLE",
+	3:  "MLE",
+	4:  "WA",
+	5:  "RE",
+	6:  "OLE",
+	7:  "CE",
+	8:  "SE",
+	10: "SPJ-TLE",
+	11: "SPJ-ERR",
+	12: "SPJ-CHK",
+	13: "FC",
 }
 
+// MemorySizeForJIT 给动态语言、带虚拟机的语言设定虚拟机自身的初始内存大小
+// 默认值由语言注册表(languages.json)中的jit_memory决定，这里的设置(jit_memory.json)优先
+var MemorySizeForJIT = map[string]int{}
+
 // PlaceMemorySizeForJIT 替换JIT虚拟内存表
 func PlaceMemorySizeForJIT(configFile string) error {
 	if configFile != "" {
@@ -36,3 +36,6 @@ var TestlibOutcomeMapping = map[string]int{
 	"partially-correct":  JudgeFlagWA,
 	"reserved":           JudgeFlagSpecialJudgeError,
 }
+
+// TestlibGeneratorTimeLimit generator's default time limit (ms)
+const TestlibGeneratorTimeLimit = 3 * 1000
@@ -17,12 +17,17 @@ import (
 )
 
 func readAndWriteToTempFile(writer io.Writer, fileName string, workDir string) error {
-	buf16 := make([]byte, 16)
-	buf32 := make([]byte, 4)
 	body, err := ioutil.ReadFile(path.Join(workDir, fileName))
 	if err != nil {
 		return err
 	}
+	return writeBodyEntry(writer, fileName, body)
+}
+
+// 写入一个body中的文件：魔数、大小、文件名(以换行结尾)、内容
+func writeBodyEntry(writer io.Writer, fileName string, body []byte) error {
+	buf16 := make([]byte, 16)
+	buf32 := make([]byte, 4)
 	binary.BigEndian.PutUint16(buf16, constants.JudgeBodyPackageMagicCode)
 	binary.BigEndian.PutUint32(buf32, uint32(len(body)))
 	if _, err := writer.Write(buf16); err != nil {
@@ -71,6 +76,18 @@ func mergeResultBinary(
 		_ = readAndWriteToTempFile(testCaseWriter, testCase.CheckerError, options.SessionDir)
 		_ = readAndWriteToTempFile(testCaseWriter, testCase.CheckerReport, options.SessionDir)
 	}
+	// 保存提交的代码，文件名以source/开头
+	if options.SaveSource && options.CodeFile != "" {
+		if info, err := os.Stat(options.CodeFile); err == nil && info.Mode().IsRegular() {
+			code, err := ioutil.ReadFile(options.CodeFile)
+			if err != nil {
+				return "", errors.Errorf("read code file error: %s", err.Error())
+			}
+			if err = writeBodyEntry(testCaseWriter, SourceEntryPrefix+path.Base(options.CodeFile), code); err != nil {
+				return "", err
+			}
+		}
+	}
 
 	return tmpFilePath, nil
 }
@@ -116,23 +116,30 @@ func validateJudgeResultPackage(pack *JudgeResultPackage) (bool, error) {
 
 // ReadJudgeResult 读取判题结果
 func ReadJudgeResult(resultFile string) (*commonStructs.JudgeResult, error) {
+	judgeResult, _, err := readJudgeResultPackage(resultFile)
+	return judgeResult, err
+}
+
+// 读取并校验判题结果数据包，body会解压到临时文件(pack.BodyPackageFile)
+func readJudgeResultPackage(resultFile string) (*commonStructs.JudgeResult, *JudgeResultPackage, error) {
 	rf, err := os.Open(resultFile)
 	if err != nil {
-		return nil, errors.Errorf("open file (%s) error: %s", resultFile, err.Error())
+		return nil, nil, errors.Errorf("open file (%s) error: %s", resultFile, err.Error())
 	}
+	defer rf.Close()
 	reader := bufio.NewReader(rf)
 
 	pack, err := parseJudgeResultBinary(reader)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	ok, err := validateJudgeResultPackage(pack)
 	if !ok || err != nil {
 		if err != nil {
-			return nil, errors.Errorf("validate package hash error: %s", err.Error())
+			return nil, nil, errors.Errorf("validate package hash error: %s", err.Error())
 		}
-		return nil, errors.Errorf("validate package hash error")
+		return nil, nil, errors.Errorf("validate package hash error")
 	}
 
 	judgeResult := commonStructs.JudgeResult{}
@@ -142,30 +149,93 @@ func ReadJudgeResult(resultFile string) (*commonStructs.JudgeResult, error) {
 	if pack.CompressorType == 1 {
 		fp, err := os.Open(pack.BodyPackageFile)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		defer fp.Close()
 		zipReader, err := gzip.NewReader(fp)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		fn := strings.Replace(pack.BodyPackageFile, ".tmp.gz", ".tmp", -1)
 		fout, err := os.Create(fn)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		defer fout.Close()
 		if _, err = io.Copy(fout, zipReader); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		_ = os.Remove(pack.BodyPackageFile)
 		pack.BodyPackageFile = fn
 	} else {
 		newPath := strings.Replace(pack.BodyPackageFile, ".tmp.gz", ".tmp", -1)
 		err := os.Rename(pack.BodyPackageFile, newPath)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		pack.BodyPackageFile = newPath
 	}
 
-	return &judgeResult, nil
+	return &judgeResult, pack, nil
+}
+
+// IsJudgeResultFile 是否为评测结果包 (按魔数判断)
+func IsJudgeResultFile(fileName string) bool {
+	fp, err := os.Open(fileName)
+	if err != nil {
+		return false
+	}
+	defer fp.Close()
+	magic := uint16(0)
+	if err := binary.Read(fp, binary.BigEndian, &magic); err != nil {
+		return false
+	}
+	return magic == constants.JudgeResultMagicCode
+}
+
+// ReadJudgeResultSources 读取判题结果，以及评测时保存的代码 (文件名 => 代码内容，文件名不含source/前缀)
+func ReadJudgeResultSources(resultFile string) (*commonStructs.JudgeResult, map[string][]byte, error) {
+	judgeResult, pack, err := readJudgeResultPackage(resultFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(pack.BodyPackageFile)
+	body, err := os.Open(pack.BodyPackageFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	sources := map[string][]byte{}
+	reader := bufio.NewReader(body)
+	buf16 := make([]byte, 16)
+	for {
+		// 魔数、大小、文件名(以换行结尾)、内容，格式见writeBodyEntry
+		if _, err := io.ReadFull(reader, buf16); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, errors.Errorf("read body entry error: %s", err.Error())
+		}
+		if binary.BigEndian.Uint16(buf16) != constants.JudgeBodyPackageMagicCode {
+			return nil, nil, errors.Errorf("read body entry error: invalid magic code")
+		}
+		size := uint32(0)
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return nil, nil, errors.Errorf("read body entry error: %s", err.Error())
+		}
+		name, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, nil, errors.Errorf("read body entry error: %s", err.Error())
+		}
+		content := make([]byte, size)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, nil, errors.Errorf("read body entry error: %s", err.Error())
+		}
+		name = strings.TrimSuffix(name, "\n")
+		if strings.HasPrefix(name, SourceEntryPrefix) {
+			sources[strings.TrimPrefix(name, SourceEntryPrefix)] = content
+		}
+	}
+	return judgeResult, sources, nil
 }
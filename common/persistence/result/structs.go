@@ -1,5 +1,8 @@
 package result
 
+// SourceEntryPrefix 评测结果包的body中，提交的代码的文件名前缀
+const SourceEntryPrefix = "source/"
+
 // JudgeResultPackage  评测结果包-数据结构
 // ------------------------
 // |MAG|VER|CMP|RSZ|BSZ|CSZ| Certificate |SSZ| Signature | Result | Body
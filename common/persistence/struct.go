@@ -24,7 +24,9 @@ type JudgeResultPersisOptions struct {
 	CommonPersisOptions
 	CompressorType   uint8
 	SessionDir       string
-	SaveAcceptedData bool // 是否保存已经AC的数据
+	SaveAcceptedData bool   // 是否保存已经AC的数据
+	SaveSource       bool   // 是否保存提交的代码 (用于相似度检测)
+	CodeFile         string // 提交的代码文件
 }
 
 // ProblemPackageOptions 题目包的持久化选项
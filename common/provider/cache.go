@@ -0,0 +1,258 @@
+package provider
+
+// Build Cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultBuildCacheSize 编译产物缓存默认的大小限制 (bytes)
+const DefaultBuildCacheSize int64 = 512 * 1024 * 1024
+
+// BuildCache 编译产物缓存，按源码、编译命令和编译器版本的SHA-256寻址
+// 超出大小限制时按最近使用时间(文件的mtime)淘汰
+type BuildCache struct {
+	Dir     string // 缓存目录
+	MaxSize int64  // 大小限制 (bytes)
+	lock    sync.Mutex
+}
+
+// 当前使用的编译产物缓存，为nil时不使用缓存 (默认关闭，通过SetBuildCache开启)
+var buildCache *BuildCache
+
+// 编译器版本 (按语言名称缓存，避免重复探测)
+var compilerVersions sync.Map
+
+// DefaultBuildCacheDir 默认的编译产物缓存目录，每个用户使用单独的目录
+func DefaultBuildCacheDir() string {
+	return path.Join(os.TempDir(), fmt.Sprintf("deer-build-cache-%d", os.Getuid()))
+}
+
+// SetBuildCache 设置编译产物缓存，maxSize不大于0时关闭缓存
+// 缓存中的程序会被直接运行，因此缓存目录只能属于当前用户，并且不能被其他用户写入
+func SetBuildCache(dir string, maxSize int64) error {
+	if maxSize <= 0 {
+		buildCache = nil
+		return nil
+	}
+	if dir == "" {
+		dir = DefaultBuildCacheDir()
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Errorf("create build cache dir error: %s", err.Error())
+	}
+	if err := checkBuildCacheDir(dir); err != nil {
+		return err
+	}
+	buildCache = &BuildCache{Dir: dir, MaxSize: maxSize}
+	return nil
+}
+
+// 检查缓存目录是否属于当前用户，并且不能被其他用户写入
+func checkBuildCacheDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return errors.Errorf("build cache dir error: %s", err.Error())
+	}
+	if !info.IsDir() {
+		return errors.Errorf("build cache dir (%s) is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return errors.Errorf("build cache dir (%s) is not owned by the current user", dir)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return errors.Errorf("build cache dir (%s) is writable by other users", dir)
+	}
+	return nil
+}
+
+// GetBuildCache 获取当前使用的编译产物缓存，关闭时返回nil
+func GetBuildCache() *BuildCache {
+	return buildCache
+}
+
+// 获取编译器版本，探测失败时为空
+func getCompilerVersion(lang *LanguageDefinition) string {
+	if version, ok := compilerVersions.Load(lang.Name); ok {
+		return version.(string)
+	}
+	version, _ := lang.Version()
+	compilerVersions.Store(lang.Name, version)
+	return version
+}
+
+// 计算包含目录中所有文件的摘要，头文件(如testlib.h)变化时缓存的键随之变化
+func hashIncludeDirs(dirs []string) string {
+	hash := sha256.New()
+	for _, dir := range dirs {
+		_, _ = fmt.Fprintf(hash, "%d:%s;", len(dir), dir)
+		_ = filepath.Walk(dir, func(fpath string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+			fp, err := os.Open(fpath)
+			if err != nil {
+				return nil
+			}
+			defer fp.Close()
+			_, _ = fmt.Fprintf(hash, "%d:%s;%d;", len(fpath), fpath, info.Size())
+			_, _ = io.Copy(hash, fp)
+			return nil
+		})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Key 计算缓存的键，每一项都带上长度，避免拼接后产生歧义
+func (cache *BuildCache) Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(hash, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// 缓存文件的路径
+func (cache *BuildCache) entryPath(key string) string {
+	return path.Join(cache.Dir, key[:2], key)
+}
+
+// 复制文件，使用临时文件+重命名保证不会读到写了一半的文件
+func copyBuildArtifact(source string, target string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := ioutil.TempFile(path.Dir(target), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, src)
+	_ = tmp.Close()
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0755)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Load 从缓存中取出编译产物，复制到target，命中时返回true
+func (cache *BuildCache) Load(key string, target string) bool {
+	entry := cache.entryPath(key)
+	if _, err := os.Stat(entry); err != nil {
+		return false
+	}
+	if err := copyBuildArtifact(entry, target); err != nil {
+		return false
+	}
+	// 更新使用时间
+	now := time.Now()
+	_ = os.Chtimes(entry, now, now)
+	return true
+}
+
+// Store 把编译产物放入缓存，并淘汰超出大小限制的部分
+func (cache *BuildCache) Store(key string, artifact string) error {
+	info, err := os.Stat(artifact)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || info.Size() > cache.MaxSize {
+		return nil
+	}
+	entry := cache.entryPath(key)
+	if err = os.MkdirAll(path.Dir(entry), 0700); err != nil {
+		return err
+	}
+	if err = copyBuildArtifact(artifact, entry); err != nil {
+		return err
+	}
+	return cache.evict()
+}
+
+// LoadText 从缓存中读取文本内容(如编译日志)，未命中时返回false
+func (cache *BuildCache) LoadText(key string) (string, bool) {
+	content, err := ioutil.ReadFile(cache.entryPath(key))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// StoreText 把文本内容(如编译日志)放入缓存
+func (cache *BuildCache) StoreText(key string, content string) error {
+	entry := cache.entryPath(key)
+	if err := os.MkdirAll(path.Dir(entry), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(path.Dir(entry), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.WriteString(content)
+	_ = tmp.Close()
+	if err == nil {
+		err = os.Rename(tmp.Name(), entry)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// 按最近使用时间淘汰缓存，直到总大小不超过限制
+func (cache *BuildCache) evict() error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	entries := make([]os.FileInfo, 0)
+	paths := map[os.FileInfo]string{}
+	var total int64
+	err := filepath.Walk(cache.Dir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 其他进程可能同时在淘汰缓存
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			entries = append(entries, info)
+			paths[info] = fpath
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil || total <= cache.MaxSize {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, info := range entries {
+		if total <= cache.MaxSize {
+			break
+		}
+		if err = os.Remove(paths[info]); err == nil || os.IsNotExist(err) {
+			total -= info.Size()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package provider
+
+// Command Template
+
+import (
+	"encoding/json"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// CommandTemplate 命令模板，每一项为一个参数
+// 可以使用的占位符：{src} 源码文件, {out} 目标程序, {dir} 工作目录, {classname} 类名, {zygote} 预热进程脚本 (只用于zygote_command),
+// {include} 头文件目录 (需要单独作为一项，会展开为若干个"-I <dir>"参数，没有时忽略)
+// 多文件提交时，单独作为一项的{src}会展开为全部源码文件
+// 在配置文件中既可以写成字符串数组，也可以写成一个字符串 (按shell的规则拆分参数，兼容旧版)
+type CommandTemplate []string
+
+// CommandVariables 命令模板中占位符对应的值
+type CommandVariables struct {
+	Source     string   // {src}
+	Sources    []string // {src} (multi-file submissions, optional)
+	Program    string   // {out}
+	WorkDir    string   // {dir}
+	ClassName  string   // {classname}
+	IncludeDir []string // {include}
+	Zygote     string   // {zygote}
+}
+
+// NewCommandTemplate 按shell的规则把命令字符串解析为命令模板
+func NewCommandTemplate(command string) (CommandTemplate, error) {
+	args, err := utils.ParseShellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	return CommandTemplate(args), nil
+}
+
+// UnmarshalJSON 支持字符串数组和字符串两种写法
+func (tpl *CommandTemplate) UnmarshalJSON(data []byte) error {
+	var args []string
+	if err := json.Unmarshal(data, &args); err == nil {
+		*tpl = args
+		return nil
+	}
+	var command string
+	if err := json.Unmarshal(data, &command); err != nil {
+		return errors.Errorf("command template should be a string or an array of strings")
+	}
+	parsed, err := NewCommandTemplate(command)
+	if err != nil {
+		return err
+	}
+	*tpl = parsed
+	return nil
+}
+
+// IsEmpty 是否为空命令
+func (tpl CommandTemplate) IsEmpty() bool {
+	return len(tpl) == 0
+}
+
+// String 转换为便于阅读的字符串，包含空白字符的参数会加上引号
+func (tpl CommandTemplate) String() string {
+	args := make([]string, len(tpl))
+	for i, arg := range tpl {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\") {
+			arg = "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+		}
+		args[i] = arg
+	}
+	return strings.Join(args, " ")
+}
+
+// Render 替换占位符，生成实际执行的参数列表
+// 占位符在参数内替换，替换后的值中即使包含空格也不会被拆分
+func (tpl CommandTemplate) Render(vars CommandVariables) []string {
+	replacer := strings.NewReplacer(
+		"{src}", vars.Source,
+		"{out}", vars.Program,
+		"{dir}", vars.WorkDir,
+		"{classname}", vars.ClassName,
+		"{zygote}", vars.Zygote,
+	)
+	args := make([]string, 0, len(tpl))
+	for _, arg := range tpl {
+		if arg == "{src}" && len(vars.Sources) > 0 {
+			args = append(args, vars.Sources...)
+			continue
+		}
+		if arg == "{include}" {
+			for _, dir := range vars.IncludeDir {
+				args = append(args, "-I", dir)
+			}
+			continue
+		}
+		args = append(args, replacer.Replace(arg))
+	}
+	return args
+}
+
+// HasPlaceholder 判断模板中是否有单独作为一项的占位符
+func (tpl CommandTemplate) HasPlaceholder(placeholder string) bool {
+	for _, arg := range tpl {
+		if arg == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOutput 判断编译结果是否为单个文件 (模板中使用了{out})
+func (tpl CommandTemplate) HasOutput() bool {
+	for _, arg := range tpl {
+		if strings.Contains(arg, "{out}") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package provider
+
+// Language Detection
+
+import (
+	"context"
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DetectConfidenceThreshold 没有可用的扩展名时，识别结果的可信度需要达到该值才会被使用
+const DetectConfidenceThreshold = 0.5
+
+// LanguageGuess 按代码内容识别语言的结果
+type LanguageGuess struct {
+	Language   *LanguageDefinition // Language definition
+	Confidence float64             // Confidence (0~1)
+	Reasons    []string            // Matched features
+}
+
+// String 转换为便于阅读的字符串
+func (guess LanguageGuess) String() string {
+	return fmt.Sprintf("%s (%.2f: %s)", guess.Language.Name, guess.Confidence, strings.Join(guess.Reasons, ", "))
+}
+
+// 语言特征，weight为命中时该语言的可信度
+type languageFeature struct {
+	language string
+	pattern  *regexp.Regexp
+	weight   float64
+	reason   string
+}
+
+func feature(language, pattern string, weight float64, reason string) languageFeature {
+	return languageFeature{language: language, pattern: regexp.MustCompile(pattern), weight: weight, reason: reason}
+}
+
+// shebang中的解释器对应的语言
+var shebangLanguages = map[string]string{
+	"python3": "python3",
+	"python2": "python2",
+	"python":  "python3",
+	"pypy3":   "pypy3",
+	"node":    "nodejs",
+	"nodejs":  "nodejs",
+	"ruby":    "ruby",
+	"php":     "php",
+	"perl":    "perl",
+	"lua":     "lua",
+	"bash":    "bash",
+	"sh":      "bash",
+}
+
+// 特征关键字和语法
+var languageFeatures = []languageFeature{
+	feature("php", `<\?php`, 0.95, "<?php"),
+	feature("g++", `(?m)^\s*#include\s*<(iostream|bits/stdc\+\+\.h|vector|string|algorithm|map|set|queue|stack|deque|list|bitset|iomanip|sstream|numeric|functional|utility|unordered_map|unordered_set)>`, 0.9, "c++ header"),
+	feature("g++", `(?m)^\s*#include\s*<c(stdio|stdlib|string|math|ctype|limits|assert|time|stdint|float)>`, 0.9, "c++ c-compat header"),
+	feature("g++", `\bstd::|using\s+namespace\s+std|\bcout\s*<<|\bcin\s*>>|\btemplate\s*<`, 0.8, "c++ syntax"),
+	feature("gcc", `(?m)^\s*#include\s*<(stdio|stdlib|string|math)\.h>`, 0.7, "c header"),
+	feature("gcc", `\b(scanf|printf)\s*\(`, 0.3, "stdio call"),
+	feature("java", `public\s+class\s+\w+|public\s+static\s+void\s+main\s*\(\s*String`, 0.85, "public class"),
+	feature("java", `(?m)^\s*import\s+java\.`, 0.9, "import java"),
+	feature("csharp", `(?m)^\s*using\s+System(\.\w+)*\s*;`, 0.9, "using System"),
+	feature("csharp", `static\s+void\s+Main\s*\(|Console\.(Write|Read)`, 0.8, "Main/Console"),
+	feature("golang", `(?m)^\s*package\s+main\b`, 0.9, "package main"),
+	feature("golang", `\bfunc\s+main\s*\(\s*\)\s*\{`, 0.6, "func main"),
+	feature("rust", `\bfn\s+main\s*\(\s*\)`, 0.9, "fn main"),
+	feature("rust", `\blet\s+mut\b|\bprintln!\s*\(|::new\(\)`, 0.6, "rust syntax"),
+	feature("kotlin", `\bfun\s+main\s*\(`, 0.9, "fun main"),
+	feature("kotlin", `\breadLine\(\)|\bval\s+\w+\s*=`, 0.4, "kotlin syntax"),
+	feature("pascal", `(?im)^\s*program\s+\w+\s*;|(?i)\bend\.\s*$`, 0.9, "program/end."),
+	feature("pascal", `(?i)\b(writeln|readln)\b|:=`, 0.4, "pascal syntax"),
+	feature("haskell", `(?m)^\s*main\s*::\s*IO|^\s*import\s+qualified\b`, 0.9, "main :: IO"),
+	feature("haskell", `(?m)^\s*main\s*=\s*(do\b|interact\b)|\$\s*map\b`, 0.6, "haskell syntax"),
+	feature("lua", `\blocal\s+\w+\s*=|\bio\.(read|write|lines)\b`, 0.7, "lua syntax"),
+	feature("lua", `(?m)\bthen\s*$|\belseif\b|~=`, 0.4, "lua keywords"),
+	feature("perl", `\bmy\s+[$@%]\w+|<STDIN>|(?m)^\s*use\s+strict\s*;`, 0.85, "perl syntax"),
+	feature("bash", `\$\(\(|(?m)^\s*(fi|done|esac)\s*$|(?m)^\s*read\s+(-\w+\s+)*\w+`, 0.7, "shell syntax"),
+	feature("nodejs", `\brequire\s*\(\s*['"]\w+['"]\s*\)|console\.log\s*\(|process\.stdin`, 0.85, "node api"),
+	feature("nodejs", `\b(const|let)\s+\w+\s*=|=>`, 0.3, "javascript syntax"),
+	feature("ruby", `\bgets\b|\bputs\b|\.each\s+do\b|\.map\(&:`, 0.7, "ruby syntax"),
+	feature("python3", `(?m)^\s*def\s+\w+\s*\(.*\)\s*(->\s*[\w\[\], .]+)?:\s*$|(?m)^\s*(import|from)\s+\w+`, 0.5, "python syntax"),
+	feature("python3", `\binput\s*\(\s*\)|\bprint\s*\(`, 0.4, "python call"),
+	feature("python3", `\binput\s*\(\s*\)\s*\.\s*(split|strip)\s*\(|\bmap\s*\(\s*int\s*,|\bsys\.stdin\.(readline|read)\b`, 0.7, "python input idiom"),
+	feature("python3", `\bprint\s*\(.*\b(end|sep)\s*=|\bf"|\bf'|\bnonlocal\b`, 0.8, "python 3 syntax"),
+	feature("python2", `(?m)^\s*print\s+[^\s(=]|\braw_input\s*\(|\bxrange\s*\(`, 0.8, "python 2 syntax"),
+}
+
+// 同时命中多个语言的特征时，以下语言的特征会覆盖另一个语言
+// 例如C++的代码通常也会命中C的特征，Ruby和Perl的print语句也会命中python2的特征
+var languageOverrides = [][2]string{
+	{"g++", "gcc"},
+	{"csharp", "java"},
+	{"java", "kotlin"},
+	{"kotlin", "nodejs"},
+	{"rust", "nodejs"},
+	{"rust", "g++"},
+	{"golang", "pascal"},
+	{"java", "python3"},
+	{"ruby", "python2"},
+	{"perl", "python2"},
+	{"python2", "python3"},
+}
+
+// 按可信度从高到低排序，可信度相同时按名称排序
+func sortLanguageGuesses(guesses []LanguageGuess) {
+	sort.SliceStable(guesses, func(i, j int) bool {
+		if guesses[i].Confidence != guesses[j].Confidence {
+			return guesses[i].Confidence > guesses[j].Confidence
+		}
+		return guesses[i].Language.Name < guesses[j].Language.Name
+	})
+}
+
+// DetectLanguage 根据代码内容(shebang、特征关键字和语法)识别语言，按可信度从高到低排序
+func DetectLanguage(code string) []LanguageGuess {
+	scores := map[string]float64{}
+	reasons := map[string][]string{}
+	hit := func(name string, weight float64, reason string) {
+		// 多个特征按概率的方式合并：1 - (1 - a)(1 - b)
+		scores[name] = 1 - (1-scores[name])*(1-weight)
+		reasons[name] = append(reasons[name], reason)
+	}
+	if strings.HasPrefix(code, "#!") {
+		line := strings.Fields(strings.SplitN(code, "\n", 2)[0][2:])
+		if len(line) > 0 {
+			interpreter := path.Base(line[0])
+			if interpreter == "env" && len(line) > 1 {
+				interpreter = line[1]
+			}
+			if name, ok := shebangLanguages[interpreter]; ok {
+				hit(name, 0.95, "shebang")
+			}
+		}
+	}
+	for _, f := range languageFeatures {
+		if f.pattern.MatchString(code) {
+			hit(f.language, f.weight, f.reason)
+		}
+	}
+	for _, item := range languageOverrides {
+		name, other := item[0], item[1]
+		if scores[name] >= DetectConfidenceThreshold && scores[other] > 0 && scores[other] <= scores[name]+0.3 {
+			scores[other] = scores[other] / 2
+		}
+	}
+
+	guesses := make([]LanguageGuess, 0, len(scores))
+	for name, score := range scores {
+		lang, ok := GetLanguage(name)
+		if !ok {
+			continue
+		}
+		guesses = append(guesses, LanguageGuess{Language: lang, Confidence: score, Reasons: reasons[name]})
+	}
+	sortLanguageGuesses(guesses)
+	return guesses
+}
+
+// MatchLanguageWithCode 匹配语言定义，keyword为auto时先按扩展名识别，
+// 扩展名对应多个语言(如.py)时按代码内容选择，没有扩展名或扩展名未知时按代码内容识别
+// 返回的guesses为按代码内容识别的结果 (用于日志)
+func MatchLanguageWithCode(keyword string, fileName string, code string) (*LanguageDefinition, []LanguageGuess, error) {
+	if keyword != "auto" && keyword != "" {
+		lang, err := MatchLanguage(keyword, fileName)
+		return lang, nil, err
+	}
+	ext := strings.ToLower(path.Ext(fileName))
+	candidates := make([]*LanguageDefinition, 0)
+	if ext != "" {
+		for _, lang := range languages {
+			if utils.Contains(lang.Extensions, ext) {
+				candidates = append(candidates, lang)
+			}
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil, nil
+	}
+	guesses := ProbeLanguageSyntax(code, DetectLanguage(code))
+	if len(candidates) > 1 {
+		for _, guess := range guesses {
+			for _, lang := range candidates {
+				if lang == guess.Language {
+					return lang, guesses, nil
+				}
+			}
+		}
+		return candidates[0], guesses, nil
+	}
+	if len(guesses) > 0 && guesses[0].Confidence >= DetectConfidenceThreshold {
+		return guesses[0].Language, guesses, nil
+	}
+	return nil, guesses, errors.Errorf("unsupported language")
+}
+
+// 语法探测的超时时间
+const syntaxProbeTimeout = 5 * time.Second
+
+// ProbeLanguageSyntax 语法探测：可信度最高的语言和其他语言共用扩展名(如python2和python3)、难以按特征区分时，
+// 使用各自的syntax_probe_command检查语法。只有一部分语言通过时，通过的语言可信度提高，没有通过的减半；
+// 解释器不存在等无法得到结果的情况不做调整
+func ProbeLanguageSyntax(code string, guesses []LanguageGuess) []LanguageGuess {
+	if len(guesses) == 0 || guesses[0].Language.SyntaxProbeCommand.IsEmpty() {
+		return guesses
+	}
+	group := []*LanguageDefinition{guesses[0].Language}
+	for _, lang := range languages {
+		if lang == guesses[0].Language || lang.SyntaxProbeCommand.IsEmpty() {
+			continue
+		}
+		for _, ext := range lang.Extensions {
+			if utils.Contains(guesses[0].Language.Extensions, ext) {
+				group = append(group, lang)
+				break
+			}
+		}
+	}
+	if len(group) < 2 {
+		return guesses
+	}
+
+	workDir, err := ioutil.TempDir("", "deer-probe-")
+	if err != nil {
+		return guesses
+	}
+	defer os.RemoveAll(workDir)
+	source := path.Join(workDir, "probe"+guesses[0].Language.Extensions[0])
+	if err = ioutil.WriteFile(source, []byte(code), 0644); err != nil {
+		return guesses
+	}
+	passed := map[*LanguageDefinition]bool{}
+	for _, lang := range group {
+		args := lang.SyntaxProbeCommand.Render(CommandVariables{Source: source, WorkDir: workDir})
+		ctx, cancel := context.WithTimeout(context.Background(), syntaxProbeTimeout)
+		ret, err := utils.RunUnixShell(&structs.ShellOptions{
+			Context: ctx,
+			Name:    args[0],
+			Args:    args[1:],
+		})
+		cancel()
+		if err != nil {
+			return guesses
+		}
+		passed[lang] = ret.Success
+	}
+	count := 0
+	for _, ok := range passed {
+		if ok {
+			count++
+		}
+	}
+	if count == 0 || count == len(group) {
+		return guesses
+	}
+
+	for _, lang := range group {
+		index := -1
+		for i, guess := range guesses {
+			if guess.Language == lang {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			guesses = append(guesses, LanguageGuess{Language: lang})
+			index = len(guesses) - 1
+		}
+		guess := &guesses[index]
+		if passed[lang] {
+			guess.Confidence = 1 - (1-guess.Confidence)*(1-0.6)
+			guess.Reasons = append(guess.Reasons, "syntax probe passed")
+		} else {
+			guess.Confidence = guess.Confidence / 2
+			guess.Reasons = append(guess.Reasons, "syntax probe failed")
+		}
+	}
+	sortLanguageGuesses(guesses)
+	return guesses
+}
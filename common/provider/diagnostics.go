@@ -0,0 +1,75 @@
+package provider
+
+// Compile Diagnostics
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// gcc/clang/javac: file:line[:column]: severity: message
+	gccDiagnosticPattern = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)?\s*(fatal error|error|warning|note):\s*(.*)$`)
+	// rustc: severity[code]: message，位置在下一行的" --> file:line:column"
+	rustDiagnosticPattern = regexp.MustCompile(`^(error|warning)(?:\[\w+\])?:\s*(.*)$`)
+	rustLocationPattern   = regexp.MustCompile(`^\s*-->\s*(.+?):(\d+):(\d+)\s*$`)
+	// go: file.go:line:column: message
+	goDiagnosticPattern = regexp.MustCompile(`^(.+?\.go):(\d+):(\d+):\s*(.*)$`)
+	// 代码和编译产物使用的UUID文件名
+	uuidFileNamePattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+// SanitizeCompileOutput 把编译器输出中的源码路径替换为中性的文件名(如main.cpp)，并去掉工作目录和UUID文件名，避免泄露评测机的路径
+func SanitizeCompileOutput(output string, sourceFile string, workDir string, neutralName string) string {
+	if sourceFile != "" && neutralName != "" {
+		output = strings.Replace(output, sourceFile, neutralName, -1)
+	}
+	if workDir = strings.TrimRight(workDir, "/"); workDir != "" {
+		output = strings.Replace(output, workDir+"/", "", -1)
+		output = strings.Replace(output, workDir, ".", -1)
+	}
+	return uuidFileNamePattern.ReplaceAllString(output, "main")
+}
+
+// ParseCompileDiagnostics 从编译器的输出中解析诊断信息，支持gcc/clang、javac、rustc和go的格式
+func ParseCompileDiagnostics(output string) []structs.CompileDiagnostic {
+	diagnostics := make([]structs.CompileDiagnostic, 0)
+	var pending *structs.CompileDiagnostic
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// rustc的诊断信息，位置在后面的行中
+		if pending != nil {
+			if matched := rustLocationPattern.FindStringSubmatch(line); matched != nil {
+				pending.File = matched[1]
+				pending.Line, _ = strconv.Atoi(matched[2])
+				pending.Column, _ = strconv.Atoi(matched[3])
+				diagnostics = append(diagnostics, *pending)
+				pending = nil
+				continue
+			}
+			if strings.TrimSpace(line) == "" || rustDiagnosticPattern.MatchString(line) {
+				// 没有位置的诊断信息(如"aborting due to previous error")
+				pending = nil
+			}
+		}
+		if matched := gccDiagnosticPattern.FindStringSubmatch(line); matched != nil {
+			item := structs.CompileDiagnostic{File: matched[1], Severity: matched[4], Message: matched[5]}
+			item.Line, _ = strconv.Atoi(matched[2])
+			item.Column, _ = strconv.Atoi(matched[3])
+			if item.Severity == "fatal error" {
+				item.Severity = "error"
+			}
+			diagnostics = append(diagnostics, item)
+		} else if matched := goDiagnosticPattern.FindStringSubmatch(line); matched != nil {
+			item := structs.CompileDiagnostic{File: matched[1], Severity: "error", Message: matched[4]}
+			item.Line, _ = strconv.Atoi(matched[2])
+			item.Column, _ = strconv.Atoi(matched[3])
+			diagnostics = append(diagnostics, item)
+		} else if matched := rustDiagnosticPattern.FindStringSubmatch(line); matched != nil {
+			pending = &structs.CompileDiagnostic{Severity: matched[1], Message: matched[2]}
+		}
+	}
+	return diagnostics
+}
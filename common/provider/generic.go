@@ -0,0 +1,181 @@
+package provider
+
+// Generic Compiler Provider
+
+import (
+	"fmt"
+	"github.com/satori/go.uuid"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// GenericCompileProvider 通用编译提供程序，编译、运行的方式由语言定义决定
+type GenericCompileProvider struct {
+	CodeCompileProvider
+	Language  *LanguageDefinition
+	className string
+}
+
+// NewGenericCompileProvider 根据语言定义创建一个编译提供程序
+func NewGenericCompileProvider(lang *LanguageDefinition) *GenericCompileProvider {
+	return &GenericCompileProvider{
+		CodeCompileProvider: CodeCompileProvider{
+			isReady:      false,
+			realTime:     lang.RealTime,
+			Name:         lang.Name,
+			compileLimit: lang.CompileLimit,
+		},
+		Language: lang,
+	}
+}
+
+// NewCompileProvider 按语言名称或别名创建一个编译提供程序
+func NewCompileProvider(keyword string) (*GenericCompileProvider, error) {
+	lang, err := MatchLanguage(keyword, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewGenericCompileProvider(lang), nil
+}
+
+// 从代码中获取类名
+func (prov *GenericCompileProvider) getClassName(code string) string {
+	return prov.Language.ClassName(code)
+}
+
+// Init 初始化
+func (prov *GenericCompileProvider) Init(code string, workDir string) error {
+	prov.codeContent = code
+	prov.workDir = workDir
+	prov.className = prov.getClassName(code)
+
+	err := prov.checkWorkDir()
+	if err != nil {
+		return err
+	}
+
+	err = prov.initFiles(prov.Language.SourceExtension(), "")
+	if err != nil {
+		return err
+	}
+	// 不需要编译的语言
+	if prov.Language.CompileCommand.IsEmpty() {
+		prov.isReady = true
+	}
+	return nil
+}
+
+func (prov *GenericCompileProvider) initFiles(codeExt string, programExt string) error {
+	if prov.Language.SourceName != "" {
+		prov.codeFileName = strings.Replace(prov.Language.SourceName, "{classname}", prov.className, -1)
+	} else {
+		prov.codeFileName = fmt.Sprintf("%s%s", uuid.NewV4().String(), codeExt)
+	}
+	prov.programFileName = fmt.Sprintf("%s%s", uuid.NewV4().String(), programExt)
+	prov.codeFilePath = path.Join(prov.workDir, prov.codeFileName)
+	prov.programFilePath = path.Join(prov.workDir, prov.programFileName)
+	return prov.saveCode()
+}
+
+// 生成命令模板的占位符变量
+func (prov *GenericCompileProvider) commandVariables() CommandVariables {
+	return CommandVariables{
+		Source:    prov.codeFilePath,
+		Program:   prov.programFilePath,
+		WorkDir:   prov.workDir,
+		ClassName: prov.className,
+	}
+}
+
+// Compile 编译程序
+func (prov *GenericCompileProvider) Compile() (result bool, errmsg string) {
+	if prov.Language.CompileCommand.IsEmpty() {
+		return true, ""
+	}
+	cacheKey := prov.buildCacheKey(prov.codeContent, nil)
+	if cacheKey != "" && buildCache.Load(cacheKey, prov.programFilePath) {
+		prov.isReady = true
+		// 编译日志和编译产物一起缓存，命中时同样可以得到警告信息
+		errmsg, _ = buildCache.LoadText(buildCache.Key(cacheKey, "log"))
+		prov.diagnostics = ParseCompileDiagnostics(errmsg)
+		return true, errmsg
+	}
+	result, errmsg = prov.shell(prov.Language.CompileCommand.Render(prov.commandVariables()))
+	errmsg = prov.collectDiagnostics(errmsg)
+	if result {
+		prov.isReady = true
+		if cacheKey != "" {
+			_ = buildCache.Store(cacheKey, prov.programFilePath)
+			_ = buildCache.StoreText(buildCache.Key(cacheKey, "log"), errmsg)
+		}
+	}
+	return
+}
+
+// 计算编译产物缓存的键，没有开启缓存或编译结果不是单个文件({out})时返回空
+func (prov *GenericCompileProvider) buildCacheKey(code string, includeDir []string) string {
+	if buildCache == nil || !prov.Language.CompileCommand.HasOutput() {
+		return ""
+	}
+	return buildCache.Key(
+		prov.Language.Name,
+		prov.Language.CompileCommand.String(),
+		getCompilerVersion(prov.Language),
+		prov.className,
+		hashIncludeDirs(includeDir),
+		code,
+	)
+}
+
+// GetRunArgs 获取运行参数
+func (prov *GenericCompileProvider) GetRunArgs() (args []string) {
+	return prov.Language.RunCommand.Render(prov.commandVariables())
+}
+
+// IsCompileError 是否编译错误
+func (prov *GenericCompileProvider) IsCompileError(remsg string) bool {
+	for _, pattern := range prov.Language.CompileErrorPatterns {
+		if regexp.MustCompile(pattern).MatchString(remsg) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExitCodeRuntimeError 非0的退出代码是否视为运行错误 (由语言定义的exit_code_as_re决定)
+func (prov *GenericCompileProvider) IsExitCodeRuntimeError(exitCode int) bool {
+	return exitCode != 0 && prov.Language.ExitCodeAsRE
+}
+
+// ManualCompile 执行手动编译，libraryDir会替换命令模板中的{include}，没有该占位符时以"-I"参数追加到命令末尾
+func (prov *GenericCompileProvider) ManualCompile(source string, target string, libraryDir []string) (bool, string) {
+	if prov.Language.CompileCommand.IsEmpty() {
+		return false, fmt.Sprintf("language (%s) cannot be compiled manually", prov.Language.Name)
+	}
+	args := prov.Language.CompileCommand.Render(CommandVariables{
+		Source:     source,
+		Program:    target,
+		WorkDir:    path.Dir(target),
+		ClassName:  prov.getClassName(""),
+		IncludeDir: libraryDir,
+	})
+	if !prov.Language.CompileCommand.HasPlaceholder("{include}") {
+		for _, v := range libraryDir {
+			args = append(args, "-I", v)
+		}
+	}
+	cacheKey := ""
+	if code, err := ioutil.ReadFile(source); err == nil {
+		cacheKey = prov.buildCacheKey(string(code), libraryDir)
+	}
+	if cacheKey != "" && buildCache.Load(cacheKey, target) {
+		return true, ""
+	}
+	ok, ceinfo := prov.shell(args)
+	if ok && cacheKey != "" {
+		_ = buildCache.Store(cacheKey, target)
+	}
+	return ok, ceinfo
+}
@@ -0,0 +1,593 @@
+package provider
+
+// Language Registry
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LanguageDefinition 语言定义
+// 命令模板中可以使用的占位符见CommandTemplate
+// 设置了family的语言为该语言的一个版本(方言)，没有设置的字段会从family继承，资源限制也可以按family设置
+type LanguageDefinition struct {
+	Name                 string          `json:"name"`                   // Language name (provider name)
+	Family               string          `json:"family"`                 // Language family, e.g. "g++" for "g++17" (optional)
+	Aliases              []string        `json:"aliases"`                // Aliases, used by --language
+	Extensions           []string        `json:"extensions"`             // Source file extensions for auto detection, the first one is used when saving code
+	SourceName           string          `json:"source_name"`            // Source file name template (optional, e.g. "{classname}.java")
+	ClassNamePattern     string          `json:"classname_pattern"`      // Regexp to find {classname} from code, default is "Main" when not matched
+	CompileCommand       CommandTemplate `json:"compile_command"`        // Compile command template (empty means no compile step)
+	RunCommand           CommandTemplate `json:"run_command"`            // Run command template
+	RealTime             bool            `json:"real_time"`              // Is real-time language (script language)
+	JITMemory            int             `json:"jit_memory"`             // JIT memory overhead (KB)
+	CompileErrorPatterns []string        `json:"compile_error_patterns"` // Regexps to detect compile error from stderr (for real-time languages)
+	ExitCodeAsRE         bool            `json:"exit_code_as_re"`        // Non-zero exit code means runtime error (e.g. uncaught exceptions of VM languages)
+	VersionCommand       CommandTemplate `json:"version_command"`        // Version probe command
+	SyntaxProbeCommand   CommandTemplate `json:"syntax_probe_command"`   // Syntax check for auto detection, tells apart languages sharing an extension, supports {src} (optional)
+	ZygoteCommand        CommandTemplate `json:"zygote_command"`         // Warm worker command, forks a child for each test case ({zygote} is the bundled worker script) (optional)
+	WarmupCommand        CommandTemplate `json:"warmup_command"`         // Run once before judging to build a startup cache, e.g. JVM class data archive (optional)
+	WarmRunCommand       CommandTemplate `json:"warm_run_command"`       // Run command using the startup cache built by warmup_command (optional)
+	WarmupCache          string          `json:"warmup_cache"`           // Startup cache file built by warmup_command, warm_run_command is used only when it exists (optional)
+	CompileLimit         CompileLimit    `json:"compile_limit"`          // Resource limits of compilation (optional, use DefaultCompileLimit when not set)
+}
+
+// python的语法探测脚本，只编译不运行 (python2和python3通用)
+const pythonSyntaxProbe = "import sys; compile(open(sys.argv[1]).read(), sys.argv[1], 'exec')"
+
+// BuiltinLanguages 内置的语言定义
+var BuiltinLanguages = []LanguageDefinition{
+	{
+		Name:           "gcc",
+		Aliases:        []string{"c", "gnu-c"},
+		Extensions:     []string{".c"},
+		CompileCommand: CommandTemplate{"gcc", "{src}", "-o", "{out}", "{include}", "-ansi", "-fno-asm", "-Wall", "-std=c11", "-lm"},
+		RunCommand:     CommandTemplate{"{out}"},
+		VersionCommand: CommandTemplate{"gcc", "--version"},
+	},
+	{
+		Name:           "g++",
+		Aliases:        []string{"cpp", "gcc-cpp", "gcpp", "cpp11", "c++11"},
+		Extensions:     []string{".cpp", ".cc", ".cxx"},
+		CompileCommand: CommandTemplate{"g++", "{src}", "-o", "{out}", "{include}", "-ansi", "-fno-asm", "-Wall", "-lm", "-std=c++11"},
+		RunCommand:     CommandTemplate{"{out}"},
+		VersionCommand: CommandTemplate{"g++", "--version"},
+	},
+	{
+		Name:             "java",
+		Extensions:       []string{".java"},
+		SourceName:       "{classname}.java",
+		ClassNamePattern: `public class ([A-Za-z0-9_$]+)`,
+		CompileCommand:   CommandTemplate{"javac", "-encoding", "utf-8", "{src}", "-d", "{dir}"},
+		RunCommand:       CommandTemplate{"/usr/bin/java", "-client", "-Dfile.encoding=utf-8", "-classpath", "{dir}", "{classname}"},
+		WarmupCommand:    CommandTemplate{"/usr/bin/java", "-XX:ArchiveClassesAtExit={dir}/warmup.jsa", "-client", "-Dfile.encoding=utf-8", "-classpath", "{dir}", "{classname}"},
+		WarmRunCommand:   CommandTemplate{"/usr/bin/java", "-XX:SharedArchiveFile={dir}/warmup.jsa", "-client", "-Dfile.encoding=utf-8", "-classpath", "{dir}", "{classname}"},
+		WarmupCache:      "{dir}/warmup.jsa",
+		JITMemory:        393216,
+		VersionCommand:   CommandTemplate{"javac", "-version"},
+		CompileLimit:     CompileLimit{TimeLimit: 20 * 1000, RealTimeLimit: 30 * 1000, MemoryLimit: 2 * 1024 * 1024, JVMHeap: 1024 * 1024},
+	},
+	// python3需要在python2之前注册，自动识别时.py优先匹配python3
+	{
+		Name:                 "python3",
+		Aliases:              []string{"py", "py3"},
+		Extensions:           []string{".py"},
+		RunCommand:           CommandTemplate{"python3", "-u", "{src}"},
+		ZygoteCommand:        CommandTemplate{"python3", "-u", "{zygote}", "{src}"},
+		RealTime:             true,
+		JITMemory:            65536,
+		CompileErrorPatterns: []string{"SyntaxError", "IndentationError", "ImportError"},
+		VersionCommand:       CommandTemplate{"python3", "--version"},
+		SyntaxProbeCommand:   CommandTemplate{"python3", "-c", pythonSyntaxProbe, "{src}"},
+	},
+	{
+		Name:                 "python2",
+		Aliases:              []string{"py2"},
+		Extensions:           []string{".py"},
+		RunCommand:           CommandTemplate{"python", "-u", "{src}"},
+		ZygoteCommand:        CommandTemplate{"python", "-u", "{zygote}", "{src}"},
+		RealTime:             true,
+		JITMemory:            65536,
+		CompileErrorPatterns: []string{"SyntaxError", "IndentationError", "ImportError"},
+		VersionCommand:       CommandTemplate{"python", "--version"},
+		SyntaxProbeCommand:   CommandTemplate{"python", "-c", pythonSyntaxProbe, "{src}"},
+	},
+	{
+		Name:           "php",
+		Extensions:     []string{".php"},
+		CompileCommand: CommandTemplate{"php", "-l", "-f", "{src}"},
+		RunCommand:     CommandTemplate{"/usr/bin/php", "-f", "{src}"},
+		RealTime:       true,
+		JITMemory:      131072,
+		VersionCommand: CommandTemplate{"php", "--version"},
+	},
+	{
+		Name:           "golang",
+		Aliases:        []string{"go"},
+		Extensions:     []string{".go"},
+		CompileCommand: CommandTemplate{"go", "build", "-o", "{out}", "{src}"},
+		RunCommand:     CommandTemplate{"{out}"},
+		VersionCommand: CommandTemplate{"go", "version"},
+		CompileLimit:   CompileLimit{TimeLimit: 20 * 1000, RealTimeLimit: 30 * 1000, MemoryLimit: 2 * 1024 * 1024},
+	},
+	{
+		Name:                 "nodejs",
+		Aliases:              []string{"node"},
+		Extensions:           []string{".js"},
+		CompileCommand:       CommandTemplate{"node", "-c", "{src}"},
+		RunCommand:           CommandTemplate{"/usr/bin/node", "{src}"},
+		RealTime:             true,
+		JITMemory:            262144,
+		CompileErrorPatterns: []string{"SyntaxError", "Error: Cannot find module"},
+		VersionCommand:       CommandTemplate{"node", "--version"},
+	},
+	{
+		Name:           "ruby",
+		Aliases:        []string{"rb"},
+		Extensions:     []string{".rb"},
+		CompileCommand: CommandTemplate{"ruby", "-c", "{src}"},
+		RunCommand:     CommandTemplate{"/usr/bin/ruby", "{src}"},
+		RealTime:       true,
+		JITMemory:      65536,
+		VersionCommand: CommandTemplate{"ruby", "--version"},
+	},
+	{
+		Name:           "rust",
+		Aliases:        []string{"rs"},
+		Extensions:     []string{".rs"},
+		CompileCommand: CommandTemplate{"rustc", "{src}", "-o", "{out}"},
+		RunCommand:     CommandTemplate{"{out}"},
+		VersionCommand: CommandTemplate{"rustc", "--version"},
+		CompileLimit:   CompileLimit{TimeLimit: 20 * 1000, RealTimeLimit: 30 * 1000, MemoryLimit: 1024 * 1024},
+	},
+	{
+		Name:           "csharp",
+		Aliases:        []string{"cs", "c#", "mono"},
+		Extensions:     []string{".cs"},
+		CompileCommand: CommandTemplate{"mcs", "-optimize+", "-out:{out}", "{src}"},
+		RunCommand:     CommandTemplate{"/usr/bin/mono", "{out}"},
+		JITMemory:      131072,
+		ExitCodeAsRE:   true,
+		VersionCommand: CommandTemplate{"mcs", "--version"},
+		CompileLimit:   CompileLimit{TimeLimit: 20 * 1000, RealTimeLimit: 30 * 1000, MemoryLimit: 1024 * 1024},
+	},
+	{
+		Name:           "kotlin",
+		Aliases:        []string{"kt"},
+		Extensions:     []string{".kt"},
+		SourceName:     "Main.kt",
+		CompileCommand: CommandTemplate{"kotlinc", "{src}", "-d", "{dir}"},
+		RunCommand:     CommandTemplate{"kotlin", "-classpath", "{dir}", "MainKt"},
+		WarmupCommand:  CommandTemplate{"kotlin", "-J-XX:ArchiveClassesAtExit={dir}/warmup.jsa", "-classpath", "{dir}", "MainKt"},
+		WarmRunCommand: CommandTemplate{"kotlin", "-J-XX:SharedArchiveFile={dir}/warmup.jsa", "-classpath", "{dir}", "MainKt"},
+		WarmupCache:    "{dir}/warmup.jsa",
+		JITMemory:      393216,
+		ExitCodeAsRE:   true,
+		VersionCommand: CommandTemplate{"kotlinc", "-version"},
+		CompileLimit:   CompileLimit{TimeLimit: 30 * 1000, RealTimeLimit: 45 * 1000, MemoryLimit: 2 * 1024 * 1024, JVMHeap: 1024 * 1024},
+	},
+	{
+		Name:           "pascal",
+		Aliases:        []string{"fpc", "pas"},
+		Extensions:     []string{".pas", ".pp"},
+		CompileCommand: CommandTemplate{"fpc", "-O2", "-Co", "-Cr", "-Ci", "-o{out}", "{src}"},
+		RunCommand:     CommandTemplate{"{out}"},
+		ExitCodeAsRE:   true,
+		VersionCommand: CommandTemplate{"fpc", "-iV"},
+	},
+	{
+		Name:           "haskell",
+		Aliases:        []string{"hs", "ghc"},
+		Extensions:     []string{".hs"},
+		CompileCommand: CommandTemplate{"ghc", "-O2", "-outputdir", "{dir}", "-o", "{out}", "{src}"},
+		RunCommand:     CommandTemplate{"{out}"},
+		JITMemory:      32768,
+		ExitCodeAsRE:   true,
+		VersionCommand: CommandTemplate{"ghc", "--version"},
+		CompileLimit:   CompileLimit{TimeLimit: 20 * 1000, RealTimeLimit: 30 * 1000, MemoryLimit: 2 * 1024 * 1024},
+	},
+	{
+		Name:                 "lua",
+		Extensions:           []string{".lua"},
+		CompileCommand:       CommandTemplate{"luac", "-p", "{src}"},
+		RunCommand:           CommandTemplate{"lua", "{src}"},
+		RealTime:             true,
+		JITMemory:            16384,
+		CompileErrorPatterns: []string{"syntax error near", "unexpected symbol near", "expected .*near"},
+		VersionCommand:       CommandTemplate{"lua", "-v"},
+	},
+	{
+		Name:                 "perl",
+		Aliases:              []string{"pl"},
+		Extensions:           []string{".pl"},
+		CompileCommand:       CommandTemplate{"perl", "-c", "{src}"},
+		RunCommand:           CommandTemplate{"perl", "{src}"},
+		RealTime:             true,
+		JITMemory:            32768,
+		CompileErrorPatterns: []string{"syntax error at", "Can't locate .* in @INC", "aborted due to compilation errors"},
+		VersionCommand:       CommandTemplate{"perl", "-v"},
+	},
+	{
+		Name:                 "bash",
+		Aliases:              []string{"sh", "shell"},
+		Extensions:           []string{".sh"},
+		CompileCommand:       CommandTemplate{"bash", "-n", "{src}"},
+		RunCommand:           CommandTemplate{"bash", "{src}"},
+		RealTime:             true,
+		JITMemory:            16384,
+		CompileErrorPatterns: []string{"syntax error near unexpected token", "syntax error: unexpected end of file"},
+		VersionCommand:       CommandTemplate{"bash", "--version"},
+	},
+}
+
+// BuiltinLanguageVariants 内置的语言版本
+var BuiltinLanguageVariants = []LanguageDefinition{
+	{
+		Name:           "clang",
+		Family:         "gcc",
+		Aliases:        []string{"clang-c"},
+		CompileCommand: CommandTemplate{"clang", "{src}", "-o", "{out}", "{include}", "-fno-asm", "-Wall", "-std=c11", "-lm"},
+		VersionCommand: CommandTemplate{"clang", "--version"},
+	},
+	{
+		Name:           "g++14",
+		Family:         "g++",
+		Aliases:        []string{"cpp14", "c++14"},
+		CompileCommand: CommandTemplate{"g++", "{src}", "-o", "{out}", "{include}", "-fno-asm", "-Wall", "-lm", "-std=c++14"},
+	},
+	{
+		Name:           "g++17",
+		Family:         "g++",
+		Aliases:        []string{"cpp17", "c++17"},
+		CompileCommand: CommandTemplate{"g++", "{src}", "-o", "{out}", "{include}", "-fno-asm", "-Wall", "-lm", "-std=c++17"},
+	},
+	{
+		Name:           "g++20",
+		Family:         "g++",
+		Aliases:        []string{"cpp20", "c++20"},
+		CompileCommand: CommandTemplate{"g++", "{src}", "-o", "{out}", "{include}", "-fno-asm", "-Wall", "-lm", "-std=c++20"},
+	},
+	{
+		Name:           "clang++17",
+		Family:         "g++",
+		Aliases:        []string{"clang-cpp17"},
+		CompileCommand: CommandTemplate{"clang++", "{src}", "-o", "{out}", "{include}", "-Wall", "-lm", "-std=c++17"},
+		VersionCommand: CommandTemplate{"clang++", "--version"},
+	},
+	{
+		Name:           "clang++20",
+		Family:         "g++",
+		Aliases:        []string{"clang-cpp20"},
+		CompileCommand: CommandTemplate{"clang++", "{src}", "-o", "{out}", "{include}", "-Wall", "-lm", "-std=c++20"},
+		VersionCommand: CommandTemplate{"clang++", "--version"},
+	},
+	{
+		Name:           "pypy3",
+		Family:         "python3",
+		RunCommand:     CommandTemplate{"pypy3", "{src}"},
+		ZygoteCommand:  CommandTemplate{"pypy3", "{zygote}", "{src}"},
+		JITMemory:      262144,
+		VersionCommand: CommandTemplate{"pypy3", "--version"},
+	},
+}
+
+// 语言注册表 (按注册顺序匹配)
+var languages = make([]*LanguageDefinition, 0, len(BuiltinLanguages))
+
+func init() {
+	for _, lang := range append(BuiltinLanguages, BuiltinLanguageVariants...) {
+		if err := RegisterLanguage(lang); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// 检查语言定义是否有效
+func (lang *LanguageDefinition) validate() error {
+	if lang.Name == "" {
+		return errors.Errorf("language name is required")
+	}
+	if lang.RunCommand.IsEmpty() {
+		return errors.Errorf("language (%s) run command is required", lang.Name)
+	}
+	for _, pattern := range append([]string{lang.ClassNamePattern}, lang.CompileErrorPatterns...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.Errorf("language (%s) has an invalid pattern: %s", lang.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// 从family继承没有设置的字段 (名称、别名和扩展名除外)
+func (lang *LanguageDefinition) inherit(family *LanguageDefinition) {
+	if lang.SourceName == "" {
+		lang.SourceName = family.SourceName
+	}
+	if lang.ClassNamePattern == "" {
+		lang.ClassNamePattern = family.ClassNamePattern
+	}
+	if lang.CompileCommand.IsEmpty() {
+		lang.CompileCommand = family.CompileCommand
+	}
+	if lang.RunCommand.IsEmpty() {
+		lang.RunCommand = family.RunCommand
+		// 预热相关的命令和运行命令使用同一个解释器/VM，只有运行命令也继承时才继承
+		if lang.ZygoteCommand.IsEmpty() {
+			lang.ZygoteCommand = family.ZygoteCommand
+		}
+		if lang.WarmupCommand.IsEmpty() && lang.WarmRunCommand.IsEmpty() {
+			lang.WarmupCommand = family.WarmupCommand
+			lang.WarmRunCommand = family.WarmRunCommand
+			lang.WarmupCache = family.WarmupCache
+		}
+	}
+	if lang.JITMemory == 0 {
+		lang.JITMemory = family.JITMemory
+	}
+	if lang.CompileErrorPatterns == nil {
+		lang.CompileErrorPatterns = family.CompileErrorPatterns
+	}
+	if lang.VersionCommand.IsEmpty() {
+		lang.VersionCommand = family.VersionCommand
+	}
+	if lang.SyntaxProbeCommand.IsEmpty() {
+		lang.SyntaxProbeCommand = family.SyntaxProbeCommand
+	}
+	if lang.CompileLimit == (CompileLimit{}) {
+		lang.CompileLimit = family.CompileLimit
+	}
+	lang.RealTime = lang.RealTime || family.RealTime
+	lang.ExitCodeAsRE = lang.ExitCodeAsRE || family.ExitCodeAsRE
+}
+
+// SourceExtension 保存代码时使用的扩展名，没有设置扩展名的语言版本使用family的设置
+func (lang *LanguageDefinition) SourceExtension() string {
+	if len(lang.Extensions) > 0 {
+		return lang.Extensions[0]
+	}
+	if family, ok := GetLanguage(lang.Family); ok && lang.Family != lang.Name {
+		return family.SourceExtension()
+	}
+	return ""
+}
+
+// ClassName 按ClassNamePattern从代码中获取类名，没有匹配时为"Main"
+func (lang *LanguageDefinition) ClassName(code string) string {
+	if lang.ClassNamePattern != "" {
+		matched := regexp.MustCompile(lang.ClassNamePattern).FindStringSubmatch(code)
+		if len(matched) > 1 {
+			return matched[1]
+		}
+	}
+	return "Main" // default public classname (might cause compile error)
+}
+
+// SourceFileName 保存代码时使用的文件名，没有设置SourceName时为"<name><扩展名>"
+func (lang *LanguageDefinition) SourceFileName(code string, name string) string {
+	if lang.SourceName != "" {
+		return strings.Replace(lang.SourceName, "{classname}", lang.ClassName(code), -1)
+	}
+	return name + lang.SourceExtension()
+}
+
+// HasName 判断名称或别名是否匹配
+func (lang *LanguageDefinition) HasName(keyword string) bool {
+	return lang.Name == keyword || utils.Contains(lang.Aliases, keyword)
+}
+
+// Version 执行版本探测命令，返回输出的第一行
+func (lang *LanguageDefinition) Version() (string, error) {
+	args := []string(lang.VersionCommand)
+	if len(args) == 0 {
+		return "", errors.Errorf("language (%s) has no version command", lang.Name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ret, err := utils.RunUnixShell(&structs.ShellOptions{
+		Context: ctx,
+		Name:    args[0],
+		Args:    args[1:],
+	})
+	if err != nil {
+		return "", err
+	}
+	// 部分编译器(如javac)会把版本信息输出到stderr
+	output := strings.TrimSpace(ret.Stdout)
+	if output == "" {
+		output = strings.TrimSpace(ret.Stderr)
+	}
+	return strings.SplitN(output, "\n", 2)[0], nil
+}
+
+// RegisterLanguage 注册语言定义，同名的定义会被替换
+func RegisterLanguage(lang LanguageDefinition) error {
+	if lang.Family != "" && lang.Family != lang.Name {
+		family, ok := GetLanguage(lang.Family)
+		if !ok {
+			return errors.Errorf("language (%s) family (%s) not registered", lang.Name, lang.Family)
+		}
+		lang.inherit(family)
+	}
+	if err := lang.validate(); err != nil {
+		return err
+	}
+	for key, item := range languages {
+		if item.Name == lang.Name {
+			languages[key] = &lang
+			return nil
+		}
+	}
+	languages = append(languages, &lang)
+	return nil
+}
+
+// RegisteredLanguages 获取全部已注册的语言定义
+func RegisteredLanguages() []*LanguageDefinition {
+	return languages
+}
+
+// GetLanguage 按名称获取语言定义
+func GetLanguage(name string) (*LanguageDefinition, bool) {
+	for _, lang := range languages {
+		if lang.Name == name {
+			return lang, true
+		}
+	}
+	return nil, false
+}
+
+// MatchLanguage 匹配语言定义，keyword为auto时按文件扩展名识别
+func MatchLanguage(keyword string, fileName string) (*LanguageDefinition, error) {
+	if keyword == "auto" || keyword == "" {
+		ext := strings.ToLower(path.Ext(fileName))
+		if ext != "" {
+			for _, lang := range languages {
+				if utils.Contains(lang.Extensions, ext) {
+					return lang, nil
+				}
+			}
+		}
+		return nil, errors.Errorf("unsupported language")
+	}
+	for _, lang := range languages {
+		if lang.HasName(keyword) {
+			return lang, nil
+		}
+	}
+	return nil, errors.Errorf("unsupported language")
+}
+
+// GetLanguageFamily 获取语言所属的family，没有设置时返回语言本身的名称
+func GetLanguageFamily(name string) string {
+	if lang, ok := GetLanguage(name); ok && lang.Family != "" {
+		return lang.Family
+	}
+	return name
+}
+
+// GetJITMemory 获取语言的JIT内存宽限 (jit_memory.json中的设置优先，其中具体语言版本的设置优先于family的设置)
+func GetJITMemory(name string) int {
+	if mem, ok := constants.MemorySizeForJIT[name]; ok {
+		return mem
+	}
+	if mem, ok := constants.MemorySizeForJIT[GetLanguageFamily(name)]; ok {
+		return mem
+	}
+	if lang, ok := GetLanguage(name); ok {
+		return lang.JITMemory
+	}
+	return 0
+}
+
+// PlaceLanguages 载入语言注册表文件(languages.json)
+// 文件内容为语言定义的数组，和已注册的同名语言按字段合并，否则作为新语言注册
+func PlaceLanguages(configFile string) error {
+	if configFile == "" {
+		return nil
+	}
+	_, err := os.Stat(configFile)
+	// ignore
+	if os.IsNotExist(err) {
+		return nil
+	}
+	cbody, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	var items []json.RawMessage
+	if err = json.Unmarshal(cbody, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		lang := LanguageDefinition{}
+		if err = json.Unmarshal(item, &lang); err != nil {
+			return err
+		}
+		if exists, ok := GetLanguage(lang.Name); ok {
+			lang = *exists
+			if err = json.Unmarshal(item, &lang); err != nil {
+				return err
+			}
+		}
+		if err = RegisterLanguage(lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 旧版编译命令集(compilers.json)中各个%s对应的占位符
+var legacyCompileCommandsMapping = map[string]struct {
+	Language     string
+	IsRunCommand bool
+	Args         []string
+}{
+	"gcc":     {Language: "gcc", Args: []string{"{src}", "{out}"}},
+	"g++":     {Language: "g++", Args: []string{"{src}", "{out}"}},
+	"java":    {Language: "java", Args: []string{"{src}", "{dir}"}},
+	"golang":  {Language: "golang", Args: []string{"{out}", "{src}"}},
+	"nodejs":  {Language: "nodejs", Args: []string{"{src}"}},
+	"php":     {Language: "php", Args: []string{"{src}"}},
+	"ruby":    {Language: "ruby", Args: []string{"{src}"}},
+	"python2": {Language: "python2", IsRunCommand: true, Args: []string{"{src}"}},
+	"python3": {Language: "python3", IsRunCommand: true, Args: []string{"{src}"}},
+	"rust":    {Language: "rust", Args: []string{"{src}", "{out}"}},
+}
+
+// PlaceCompilerCommands 替换编译命令集 (兼容旧版的compilers.json)
+func PlaceCompilerCommands(configFile string) error {
+	if configFile == "" {
+		return nil
+	}
+	_, err := os.Stat(configFile)
+	// ignore
+	if os.IsNotExist(err) {
+		return nil
+	}
+	cbody, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	commands := map[string]string{}
+	if err = json.Unmarshal(cbody, &commands); err != nil {
+		return err
+	}
+	for key, command := range commands {
+		mapping, ok := legacyCompileCommandsMapping[key]
+		if !ok || command == "" {
+			continue
+		}
+		lang, ok := GetLanguage(mapping.Language)
+		if !ok {
+			continue
+		}
+		tpl, err := NewCommandTemplate(command)
+		if err != nil {
+			return errors.Errorf("compiler command (%s) parse error: %s", key, err.Error())
+		}
+		// 按出现的顺序把%s替换为对应的占位符
+		placeholders := mapping.Args
+		for i := range tpl {
+			for strings.Contains(tpl[i], "%s") && len(placeholders) > 0 {
+				tpl[i] = strings.Replace(tpl[i], "%s", placeholders[0], 1)
+				placeholders = placeholders[1:]
+			}
+		}
+		if mapping.IsRunCommand {
+			lang.RunCommand = tpl
+		} else {
+			lang.CompileCommand = tpl
+		}
+	}
+	return nil
+}
@@ -3,48 +3,14 @@ package provider
 // Compiler Provider Base
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/LanceLRQ/deer-executor/v2/common/structs"
-	"github.com/LanceLRQ/deer-executor/v2/common/utils"
 	"github.com/pkg/errors"
 	"github.com/satori/go.uuid"
-	"io/ioutil"
 	"os"
 	"path"
-	"strings"
-	"time"
 )
 
-// CompileCommandsStruct 编译命令集
-type CompileCommandsStruct struct {
-	GNUC    string `json:"gcc"`
-	GNUCPP  string `json:"g++"`
-	Java    string `json:"java"`
-	Go      string `json:"golang"`
-	NodeJS  string `json:"nodejs"`
-	PHP     string `json:"php"`
-	Ruby    string `json:"ruby"`
-	Python2 string `json:"python2"`
-	Python3 string `json:"python3"`
-	Rust    string `json:"rust"`
-}
-
-// CompileCommands 定义默认的编译命令集
-var CompileCommands = CompileCommandsStruct{
-	GNUC:    "gcc %s -o %s -ansi -fno-asm -Wall -std=c11 -lm",
-	GNUCPP:  "g++ %s -o %s -ansi -fno-asm -Wall -lm -std=c++11",
-	Java:    "javac -encoding utf-8 %s -d %s",
-	Go:      "go build -o %s %s",
-	NodeJS:  "node -c %s",
-	PHP:     "php -l -f %s",
-	Ruby:    "ruby -c %s",
-	Python2: "python -u %s",
-	Python3: "python3 -u %s",
-	Rust:    "rustc %s -o %s",
-}
-
 // CodeCompileProviderInterface 代码编译提供程序接口定义
 type CodeCompileProviderInterface interface {
 	// 初始化
@@ -53,18 +19,28 @@ type CodeCompileProviderInterface interface {
 	initFiles(codeExt string, programExt string) error
 	// 执行编译
 	Compile() (result bool, errmsg string)
+	// 获取编译器输出的诊断信息 (编译成功时也会保留警告)
+	Diagnostics() []structs.CompileDiagnostic
+	// 设置编译的硬超时(ms)
+	SetCompileTimeout(timeout int)
 	// 清理工作目录
 	Clean()
 	// 获取程序的运行命令参数组
 	GetRunArgs() (args []string)
+	// 获取预热进程(zygote)的启动参数，语言不支持时返回nil
+	GetZygoteArgs() ([]string, error)
+	// 获取预热命令、使用预热结果的运行命令以及预热生成的缓存文件，语言不支持时返回nil
+	GetWarmupArgs() (warmup []string, run []string, cache string)
 	// 判断STDERR的输出内容是否存在编译错误信息，通常用于脚本语言的判定，
 	IsCompileError(remsg string) bool
+	// 非0的退出代码是否视为运行错误
+	IsExitCodeRuntimeError(exitCode int) bool
 	// 是否为实时编译的语言
 	IsRealTime() bool
 	// 是否已经编译完毕
 	IsReady() bool
 	// 调用Shell命令并获取运行结果
-	shell(commands string) (success bool, errout string)
+	shell(args []string) (success bool, errout string)
 	// 保存代码到文件
 	saveCode() error
 	// 检查工作目录是否存在
@@ -76,33 +52,15 @@ type CodeCompileProviderInterface interface {
 // CodeCompileProvider 代码编译提供程序公共结构定义
 type CodeCompileProvider struct {
 	CodeCompileProviderInterface
-	Name                             string // 编译器提供程序名称
-	codeContent                      string // 代码
-	realTime                         bool   // 是否为实时编译的语言
-	isReady                          bool   // 是否已经编译完毕
-	codeFileName, codeFilePath       string // 目标程序源文件
-	programFileName, programFilePath string // 目标程序文件
-	workDir                          string // 工作目录
-}
-
-// PlaceCompilerCommands 替换编译命令集
-func PlaceCompilerCommands(configFile string) error {
-	if configFile != "" {
-		_, err := os.Stat(configFile)
-		// ignore
-		if os.IsNotExist(err) {
-			return nil
-		}
-		cbody, err := ioutil.ReadFile(configFile)
-		if err != nil {
-			return err
-		}
-		err = json.Unmarshal(cbody, &CompileCommands)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	Name                             string                      // 编译器提供程序名称
+	codeContent                      string                      // 代码
+	realTime                         bool                        // 是否为实时编译的语言
+	isReady                          bool                        // 是否已经编译完毕
+	codeFileName, codeFilePath       string                      // 目标程序源文件
+	programFileName, programFilePath string                      // 目标程序文件
+	workDir                          string                      // 工作目录
+	compileLimit                     CompileLimit                // 编译资源限制
+	diagnostics                      []structs.CompileDiagnostic // 编译器输出的诊断信息
 }
 
 // 初始化文件
@@ -121,33 +79,60 @@ func (prov *CodeCompileProvider) GetName() string {
 	return prov.Name
 }
 
+// GetZygoteArgs 默认不支持预热进程
+func (prov *CodeCompileProvider) GetZygoteArgs() ([]string, error) {
+	return nil, nil
+}
+
+// GetWarmupArgs 默认不支持预热
+func (prov *CodeCompileProvider) GetWarmupArgs() (warmup []string, run []string, cache string) {
+	return nil, nil, ""
+}
+
+// Diagnostics 获取编译器输出的诊断信息
+func (prov *CodeCompileProvider) Diagnostics() []structs.CompileDiagnostic {
+	return prov.diagnostics
+}
+
+// SetCompileTimeout 设置编译的硬超时(ms)，只会收紧语言的编译时间限制，0表示不设置
+func (prov *CodeCompileProvider) SetCompileTimeout(timeout int) {
+	if timeout <= 0 {
+		return
+	}
+	limit := prov.compileLimit.withDefault()
+	if timeout < limit.TimeLimit {
+		limit.TimeLimit = timeout
+	}
+	if timeout < limit.RealTimeLimit {
+		limit.RealTimeLimit = timeout
+	}
+	prov.compileLimit = limit
+}
+
+// 整理编译器的输出：替换源码路径和工作目录，并解析出诊断信息
+// 源码使用UUID文件名时替换为main加扩展名，语言要求固定文件名(如Main.java)时保留原名
+func (prov *CodeCompileProvider) collectDiagnostics(output string) string {
+	neutralName := prov.codeFileName
+	if uuidFileNamePattern.MatchString(neutralName) {
+		neutralName = "main" + path.Ext(neutralName)
+	}
+	output = SanitizeCompileOutput(output, prov.codeFilePath, prov.workDir, neutralName)
+	prov.diagnostics = ParseCompileDiagnostics(output)
+	return output
+}
+
 // Clean 清理代码
 func (prov *CodeCompileProvider) Clean() {
 	_ = os.Remove(prov.codeFilePath)
 	_ = os.Remove(prov.programFilePath)
 }
 
-// 执行shell
-func (prov *CodeCompileProvider) shell(commands string) (success bool, errout string) {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-	cmdArgs := strings.Split(commands, " ")
-	if len(cmdArgs) <= 1 {
+// 执行编译命令 (在沙箱中运行，受语言的编译资源限制约束)
+func (prov *CodeCompileProvider) shell(args []string) (success bool, errout string) {
+	if len(args) <= 1 {
 		return false, "not enough arguments for compiler"
 	}
-	ret, err := utils.RunUnixShell(&structs.ShellOptions{
-		Context:   ctx,
-		Name:      cmdArgs[0],
-		Args:      cmdArgs[1:],
-		StdWriter: nil,
-		OnStart:   nil,
-	})
-	if err != nil {
-		return false, err.Error()
-	}
-	if !ret.Success {
-		return false, ret.Stderr
-	}
-	return true, ""
+	return runCompileInSandbox(args, prov.workDir, prov.compileLimit)
 }
 
 // 存储代码到文件
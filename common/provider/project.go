@@ -0,0 +1,368 @@
+package provider
+
+// Project (Multi-file Submission) Compiler Provider
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 编译时需要传入全部源码文件的语言 (按family)
+var projectMultiSourceFamilies = []string{"gcc", "g++", "java", "golang"}
+
+// 按约定的文件名查找入口文件 (按family)
+var projectEntryFiles = map[string][]string{
+	"python3": {"main.py", "__main__.py"},
+	"python2": {"main.py", "__main__.py"},
+	"nodejs":  {"main.js", "index.js"},
+	"php":     {"main.php", "index.php"},
+	"ruby":    {"main.rb"},
+	"rust":    {"main.rs", "src/main.rs"},
+}
+
+// 按代码内容查找入口文件 (按family)
+var projectEntryPatterns = map[string]string{
+	"gcc":    `\bmain\s*\(`,
+	"g++":    `\bmain\s*\(`,
+	"java":   `public\s+static\s+void\s+main\s*\(`,
+	"golang": `func\s+main\s*\(`,
+	"rust":   `fn\s+main\s*\(`,
+}
+
+// ProjectCompileProvider 多文件提交的编译提供程序
+// 构建方式的优先级：自定义构建脚本 > Makefile/CMakeLists.txt (C/C++) > Cargo.toml (Rust) > 语言的编译命令
+type ProjectCompileProvider struct {
+	GenericCompileProvider
+	ProjectDir string                 // 项目根目录
+	options    structs.ProjectOptions // 多文件提交设置
+	entry      string                 // 入口文件
+	sources    []string               // 全部源码文件
+	runArgs    []string               // 自定义构建后的运行参数
+}
+
+// NewProjectCompileProvider 创建多文件提交的编译提供程序，keyword为auto时按项目内容识别语言
+// options.BuildScript需要是绝对路径
+func NewProjectCompileProvider(keyword string, projectDir string, options structs.ProjectOptions) (*ProjectCompileProvider, error) {
+	var lang *LanguageDefinition
+	var err error
+	if keyword == "auto" || keyword == "" {
+		lang, err = DetectProjectLanguage(projectDir)
+	} else {
+		lang, err = MatchLanguage(keyword, "")
+	}
+	if err != nil {
+		// 使用自定义构建脚本时可以不限定语言
+		if options.BuildScript == "" {
+			return nil, err
+		}
+		lang = &LanguageDefinition{Name: "project"}
+	}
+	return &ProjectCompileProvider{
+		GenericCompileProvider: *NewGenericCompileProvider(lang),
+		ProjectDir:             projectDir,
+		options:                options,
+	}, nil
+}
+
+// ListProjectFiles 列出项目中的全部文件 (相对路径)
+func ListProjectFiles(projectDir string) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.Walk(projectDir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			rel, err := filepath.Rel(projectDir, fpath)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// DetectProjectLanguage 识别项目的语言：有Cargo.toml的为rust，否则按源码文件数量最多的语言
+func DetectProjectLanguage(projectDir string) (*LanguageDefinition, error) {
+	if _, err := os.Stat(path.Join(projectDir, "Cargo.toml")); err == nil {
+		if lang, ok := GetLanguage("rust"); ok {
+			return lang, nil
+		}
+	}
+	files, err := ListProjectFiles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	counter := map[string]int{}
+	var detected *LanguageDefinition
+	for _, file := range files {
+		lang, err := MatchLanguage("auto", file)
+		if err != nil {
+			continue
+		}
+		counter[lang.Name]++
+		if detected == nil || counter[lang.Name] > counter[detected.Name] {
+			detected = lang
+		}
+	}
+	if detected == nil {
+		return nil, errors.Errorf("unsupported language")
+	}
+	return detected, nil
+}
+
+// 语言的源码扩展名，语言版本使用family的设置
+func (prov *ProjectCompileProvider) sourceExtensions() []string {
+	if len(prov.Language.Extensions) > 0 {
+		return prov.Language.Extensions
+	}
+	if family, ok := GetLanguage(prov.Language.Family); ok {
+		return family.Extensions
+	}
+	return nil
+}
+
+// 查找项目的入口文件
+func (prov *ProjectCompileProvider) findEntry(family string) (string, error) {
+	if prov.options.Entry != "" {
+		entry, err := utils.SafeJoin(prov.ProjectDir, prov.options.Entry)
+		if err != nil {
+			return "", err
+		}
+		if _, err = os.Stat(entry); err != nil {
+			return "", errors.Errorf("entry file (%s) not exists", prov.options.Entry)
+		}
+		return entry, nil
+	}
+	for _, name := range projectEntryFiles[family] {
+		entry := path.Join(prov.ProjectDir, name)
+		if _, err := os.Stat(entry); err == nil {
+			return entry, nil
+		}
+	}
+	if pattern, ok := projectEntryPatterns[family]; ok {
+		reg := regexp.MustCompile(pattern)
+		entries := make([]string, 0)
+		for _, source := range prov.sources {
+			code, err := ioutil.ReadFile(source)
+			if err != nil {
+				return "", err
+			}
+			if reg.Match(code) {
+				entries = append(entries, source)
+			}
+		}
+		if len(entries) == 1 {
+			return entries[0], nil
+		}
+		if len(entries) > 1 {
+			return "", errors.Errorf("found %d entry files, please keep only one", len(entries))
+		}
+	}
+	if len(prov.sources) == 1 {
+		return prov.sources[0], nil
+	}
+	return "", errors.Errorf("cannot find the entry file of the project")
+}
+
+// java的完整类名 (包名 + 文件名)
+func (prov *ProjectCompileProvider) getJavaClassName(entry string) string {
+	className := strings.TrimSuffix(path.Base(entry), path.Ext(entry))
+	code, err := ioutil.ReadFile(entry)
+	if err == nil {
+		matched := regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`).FindSubmatch(code)
+		if len(matched) > 1 {
+			return string(matched[1]) + "." + className
+		}
+	}
+	return className
+}
+
+// Init 初始化，code参数不会被使用，源码来自项目目录
+func (prov *ProjectCompileProvider) Init(code string, workDir string) error {
+	prov.workDir = workDir
+	err := prov.checkWorkDir()
+	if err != nil {
+		return err
+	}
+	prov.programFilePath = path.Join(prov.ProjectDir, "main")
+	// 自定义构建脚本、Makefile等方式不需要识别入口文件
+	if prov.options.BuildScript != "" || prov.buildSystem() != "" {
+		return nil
+	}
+
+	files, err := ListProjectFiles(prov.ProjectDir)
+	if err != nil {
+		return err
+	}
+	extensions := prov.sourceExtensions()
+	prov.sources = make([]string, 0)
+	for _, file := range files {
+		if utils.Contains(extensions, strings.ToLower(path.Ext(file))) {
+			prov.sources = append(prov.sources, path.Join(prov.ProjectDir, file))
+		}
+	}
+	if len(prov.sources) == 0 {
+		return errors.Errorf("no %s source file found in project", prov.Language.Name)
+	}
+	family := GetLanguageFamily(prov.Language.Name)
+	prov.entry, err = prov.findEntry(family)
+	if err != nil {
+		return err
+	}
+	prov.codeFilePath = prov.entry
+	prov.codeFileName = path.Base(prov.entry)
+	if family == "java" {
+		prov.className = prov.getJavaClassName(prov.entry)
+	} else {
+		prov.className = prov.getClassName("")
+	}
+	if prov.Language.CompileCommand.IsEmpty() {
+		prov.isReady = true
+	}
+	return nil
+}
+
+// 项目使用的构建系统 (make, cmake, cargo)，没有时返回空
+func (prov *ProjectCompileProvider) buildSystem() string {
+	exists := func(name string) bool {
+		_, err := os.Stat(path.Join(prov.ProjectDir, name))
+		return err == nil
+	}
+	switch GetLanguageFamily(prov.Language.Name) {
+	case "gcc", "g++":
+		if exists("Makefile") || exists("makefile") {
+			return "make"
+		}
+		if exists("CMakeLists.txt") {
+			return "cmake"
+		}
+	case "rust":
+		if exists("Cargo.toml") {
+			return "cargo"
+		}
+	}
+	return ""
+}
+
+// 读取Cargo.toml中的包名
+func (prov *ProjectCompileProvider) getCargoPackageName() string {
+	content, err := ioutil.ReadFile(path.Join(prov.ProjectDir, "Cargo.toml"))
+	if err == nil {
+		matched := regexp.MustCompile(`(?m)^\s*name\s*=\s*"([^"]+)"`).FindSubmatch(content)
+		if len(matched) > 1 {
+			return string(matched[1])
+		}
+	}
+	return "main"
+}
+
+// 在项目目录中依次执行构建命令，返回所有命令的输出 (已替换项目目录的路径)
+func (prov *ProjectCompileProvider) runBuildCommands(commands ...[]string) (bool, string) {
+	output := ""
+	for _, args := range commands {
+		success, errmsg := runCompileInSandbox(args, prov.ProjectDir, prov.compileLimit)
+		output += errmsg
+		if !success {
+			return false, prov.collectProjectDiagnostics(output)
+		}
+	}
+	return true, prov.collectProjectDiagnostics(output)
+}
+
+// 整理构建输出：项目中的文件保留相对路径，只去掉项目目录和工作目录
+func (prov *ProjectCompileProvider) collectProjectDiagnostics(output string) string {
+	output = SanitizeCompileOutput(output, "", prov.ProjectDir, "")
+	output = SanitizeCompileOutput(output, "", prov.workDir, "")
+	prov.diagnostics = ParseCompileDiagnostics(output)
+	return output
+}
+
+// Compile 编译项目
+func (prov *ProjectCompileProvider) Compile() (result bool, errmsg string) {
+	vars := CommandVariables{WorkDir: prov.ProjectDir}
+	switch {
+	case prov.options.BuildScript != "":
+		if len(prov.options.RunCommand) == 0 {
+			return false, "run command of the custom build script is not set"
+		}
+		result, errmsg = prov.runBuildCommands([]string{"/bin/sh", prov.options.BuildScript})
+		prov.runArgs = CommandTemplate(prov.options.RunCommand).Render(vars)
+	case prov.buildSystem() == "make":
+		result, errmsg = prov.runBuildCommands([]string{"make", "-C", prov.ProjectDir})
+		prov.runArgs = []string{prov.programFilePath}
+	case prov.buildSystem() == "cmake":
+		buildDir := path.Join(prov.ProjectDir, "build")
+		result, errmsg = prov.runBuildCommands(
+			[]string{"cmake", "-S", prov.ProjectDir, "-B", buildDir},
+			[]string{"cmake", "--build", buildDir},
+		)
+		prov.runArgs = []string{path.Join(buildDir, "main")}
+	case prov.buildSystem() == "cargo":
+		result, errmsg = prov.runBuildCommands([]string{
+			"cargo", "build", "--release", "--offline", "--manifest-path", path.Join(prov.ProjectDir, "Cargo.toml"),
+		})
+		prov.runArgs = []string{path.Join(prov.ProjectDir, "target", "release", prov.getCargoPackageName())}
+	case prov.Language.CompileCommand.IsEmpty():
+		return true, ""
+	default:
+		vars = prov.commandVariables()
+		if utils.Contains(projectMultiSourceFamilies, GetLanguageFamily(prov.Language.Name)) {
+			vars.Sources = prov.sources
+		}
+		result, errmsg = prov.runBuildCommands(prov.Language.CompileCommand.Render(vars))
+	}
+	if result {
+		prov.isReady = true
+	}
+	return
+}
+
+// 生成命令模板的占位符变量，工作目录为项目根目录
+func (prov *ProjectCompileProvider) commandVariables() CommandVariables {
+	return CommandVariables{
+		Source:     prov.entry,
+		Program:    prov.programFilePath,
+		WorkDir:    prov.ProjectDir,
+		ClassName:  prov.className,
+		IncludeDir: []string{prov.ProjectDir},
+	}
+}
+
+// GetRunArgs 获取运行参数
+func (prov *ProjectCompileProvider) GetRunArgs() (args []string) {
+	if prov.runArgs != nil {
+		return prov.runArgs
+	}
+	return prov.Language.RunCommand.Render(prov.commandVariables())
+}
+
+// Clean 清理项目目录
+func (prov *ProjectCompileProvider) Clean() {
+	_ = os.RemoveAll(prov.ProjectDir)
+}
+
+// GetEntry 获取识别到的入口文件
+func (prov *ProjectCompileProvider) GetEntry() string {
+	return prov.entry
+}
+
+// String 项目的描述信息
+func (prov *ProjectCompileProvider) String() string {
+	if prov.entry == "" {
+		return fmt.Sprintf("%s project", prov.Language.Name)
+	}
+	rel, _ := filepath.Rel(prov.ProjectDir, prov.entry)
+	return fmt.Sprintf("%s project (entry: %s)", prov.Language.Name, rel)
+}
@@ -0,0 +1,189 @@
+package provider
+
+// Sandboxed Compilation
+
+import (
+	"context"
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/cmd"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/forkexec"
+	"github.com/satori/go.uuid"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CompileLimitExceeded 编译超出资源限制时，编译错误信息的前缀
+const CompileLimitExceeded = "Compile Limit Exceeded"
+
+// CompileLimit 编译时的资源限制，为0的项使用默认值
+type CompileLimit struct {
+	TimeLimit     int `json:"time_limit"`      // CPU time limit (ms)
+	RealTimeLimit int `json:"real_time_limit"` // Real time limit (ms)
+	MemoryLimit   int `json:"memory_limit"`    // Memory limit (KB)
+	OutputLimit   int `json:"output_limit"`    // Output file size limit (bytes), for compile target and compiler messages
+	JVMHeap       int `json:"jvm_heap"`        // Heap limit of JVM compilers (KB), passed as -J-Xmx instead of limiting the address space (optional)
+}
+
+// DefaultCompileLimit 默认的编译资源限制
+var DefaultCompileLimit = CompileLimit{
+	TimeLimit:     10 * 1000,
+	RealTimeLimit: 15 * 1000,
+	MemoryLimit:   512 * 1024,
+	OutputLimit:   64 * 1024 * 1024,
+}
+
+// 编译错误信息中用于判断内存不足的关键字
+var compileOutOfMemoryMessages = []string{
+	"memory exhausted",
+	"out of memory",
+	"Cannot allocate memory",
+	"std::bad_alloc",
+	"Could not reserve enough space",
+	"java.lang.OutOfMemoryError",
+}
+
+// 使用默认值填充没有设置的项
+func (limit CompileLimit) withDefault() CompileLimit {
+	if limit.TimeLimit <= 0 {
+		limit.TimeLimit = DefaultCompileLimit.TimeLimit
+	}
+	if limit.RealTimeLimit <= 0 {
+		limit.RealTimeLimit = DefaultCompileLimit.RealTimeLimit
+	}
+	if limit.MemoryLimit <= 0 {
+		limit.MemoryLimit = DefaultCompileLimit.MemoryLimit
+	}
+	if limit.OutputLimit <= 0 {
+		limit.OutputLimit = DefaultCompileLimit.OutputLimit
+	}
+	return limit
+}
+
+// 分析编译进程的退出状态，超出资源限制时返回对应的说明
+func analysisCompileLimit(limit CompileLimit, status syscall.WaitStatus, rusage *syscall.Rusage, timeout bool, message string) string {
+	if timeout {
+		return fmt.Sprintf("real time limit (%dms) exceeded", limit.RealTimeLimit)
+	}
+	if status.Signaled() {
+		switch status.Signal() {
+		case syscall.SIGXCPU:
+			return fmt.Sprintf("time limit (%dms) exceeded", limit.TimeLimit)
+		case syscall.SIGALRM:
+			return fmt.Sprintf("real time limit (%dms) exceeded", limit.RealTimeLimit)
+		case syscall.SIGXFSZ:
+			return fmt.Sprintf("output limit (%d bytes) exceeded", limit.OutputLimit)
+		}
+	}
+	if status.ExitStatus() == 0 && !status.Signaled() {
+		return ""
+	}
+	if rusage != nil {
+		tu := int(rusage.Utime.Sec*1000 + int64(rusage.Utime.Usec)/1000 + rusage.Stime.Sec*1000 + int64(rusage.Stime.Usec)/1000)
+		if tu >= limit.TimeLimit {
+			return fmt.Sprintf("time limit (%dms) exceeded", limit.TimeLimit)
+		}
+	}
+	for _, msg := range compileOutOfMemoryMessages {
+		if strings.Contains(message, msg) {
+			return fmt.Sprintf("memory limit (%dKB) exceeded", limit.MemoryLimit)
+		}
+	}
+	return ""
+}
+
+// 在沙箱中执行编译命令，编译器的stdout和stderr会作为错误信息返回 (编译成功时为警告等诊断信息)
+func runCompileInSandbox(args []string, workDir string, limit CompileLimit) (success bool, errout string) {
+	limit = limit.withDefault()
+	programPath := args[0]
+	if path.Base(programPath) == programPath {
+		var err error
+		if programPath, err = exec.LookPath(programPath); err != nil {
+			return false, err.Error()
+		}
+	}
+	if workDir == "" {
+		workDir = os.TempDir()
+	}
+	memoryLimit := limit.MemoryLimit
+	if limit.JVMHeap > 0 {
+		// JVM启动时预留的虚拟内存远大于实际使用，不限制地址空间，改为通过-J-Xmx限制堆的大小
+		args = append([]string{args[0], fmt.Sprintf("-J-Xmx%dk", limit.JVMHeap)}, args[1:]...)
+		memoryLimit = 0
+	}
+	msgFile := path.Join(workDir, uuid.NewV4().String()+".compile.log")
+	defer os.Remove(msgFile)
+
+	stdin, err := os.OpenFile(os.DevNull, os.O_RDONLY, 0)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer stdin.Close()
+	stdout, err := os.OpenFile(msgFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer stdout.Close()
+
+	proc, err := cmd.StartProcess(programPath, args, &cmd.ProcAttr{
+		Dir:   workDir,
+		Env:   os.Environ(),
+		Files: []interface{}{stdin, stdout, stdout},
+		Sys: &forkexec.SysProcAttr{
+			// 编译器驱动程序(如g++)会派生cc1plus、as、ld等子进程，超时时需要结束整个进程组
+			Setpgid: true,
+			Rlimit: forkexec.ExecRLimit{
+				TimeLimit:     limit.TimeLimit,
+				RealTimeLimit: limit.RealTimeLimit,
+				MemoryLimit:   memoryLimit,
+				FileSizeLimit: limit.OutputLimit,
+				StackLimit:    -1,
+			},
+		},
+	})
+	if err != nil {
+		return false, err.Error()
+	}
+
+	// 编译器派生的子进程不受setitimer影响，额外加一层超时保护
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(limit.RealTimeLimit+1000)*time.Millisecond)
+	defer cancel()
+	waited := make(chan *cmd.ProcessState, 1)
+	var waitErr error
+	go func() {
+		var state *cmd.ProcessState
+		state, waitErr = proc.Wait()
+		waited <- state
+	}()
+	timeout := false
+	var state *cmd.ProcessState
+	select {
+	case state = <-waited:
+	case <-ctx.Done():
+		timeout = true
+		_ = syscall.Kill(-proc.Pid, syscall.SIGKILL)
+		state = <-waited
+	}
+	if waitErr != nil {
+		return false, waitErr.Error()
+	}
+
+	msgBytes, _ := ioutil.ReadFile(msgFile)
+	message := string(msgBytes)
+	status := state.Sys().(syscall.WaitStatus)
+	rusage, _ := state.SysUsage().(*syscall.Rusage)
+	if reason := analysisCompileLimit(limit, status, rusage, timeout, message); reason != "" {
+		return false, fmt.Sprintf("%s: %s\n%s", CompileLimitExceeded, reason, message)
+	}
+	if !state.Success() {
+		if status.Signaled() {
+			message += fmt.Sprintf("compiler terminated by signal: %s\n", status.Signal().String())
+		}
+		return false, message
+	}
+	return true, message
+}
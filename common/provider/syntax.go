@@ -0,0 +1,119 @@
+package provider
+
+// Source Syntax
+
+import "strings"
+
+// SourceSyntax 语言的注释和字符串语法
+type SourceSyntax struct {
+	lineComments  []string    // 单行注释
+	blockComments [][2]string // 多行注释
+	quotes        []string    // 字符串的引号，长的在前 (如python的三引号)
+	rawQuotes     []string    // 不处理转义的引号 (如go的反引号)
+}
+
+var (
+	cSourceSyntax      = SourceSyntax{lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}}
+	hashSourceSyntax   = SourceSyntax{lineComments: []string{"#"}, quotes: []string{`"`, `'`}}
+	pythonSourceSyntax = SourceSyntax{lineComments: []string{"#"}, quotes: []string{`"""`, `'''`, `"`, `'`}}
+)
+
+// 各语言的注释和字符串语法 (按family)
+var sourceSyntaxes = map[string]SourceSyntax{
+	"gcc":     cSourceSyntax,
+	"g++":     cSourceSyntax,
+	"java":    cSourceSyntax,
+	"csharp":  cSourceSyntax,
+	"kotlin":  cSourceSyntax,
+	"golang":  {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}, rawQuotes: []string{"`"}},
+	"nodejs":  {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}, rawQuotes: []string{"`"}},
+	"rust":    {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`}}, // 单引号也用于生命周期
+	"php":     {lineComments: []string{"//", "#"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}},
+	"python3": pythonSourceSyntax,
+	"python2": pythonSourceSyntax,
+	"ruby":    hashSourceSyntax,
+	"perl":    hashSourceSyntax,
+	"bash":    hashSourceSyntax,
+	"lua":     {lineComments: []string{"--"}, quotes: []string{`"`, `'`}},
+	"haskell": {lineComments: []string{"--"}, blockComments: [][2]string{{"{-", "-}"}}, quotes: []string{`"`}},
+	"pascal":  {lineComments: []string{"//"}, blockComments: [][2]string{{"{", "}"}, {"(*", "*)"}}, quotes: []string{`'`}},
+}
+
+// GetSourceSyntax 获取语言的注释和字符串语法，依次按family和语言名称查找
+func GetSourceSyntax(langName string) (SourceSyntax, bool) {
+	if syntax, ok := sourceSyntaxes[GetLanguageFamily(langName)]; ok {
+		return syntax, true
+	}
+	syntax, ok := sourceSyntaxes[langName]
+	return syntax, ok
+}
+
+func hasPrefixAt(code string, i int, prefix string) bool {
+	return strings.HasPrefix(code[i:], prefix)
+}
+
+// MaskSource 把代码中的注释(以及字符串的内容)替换为空格，保留换行和偏移量，使得行号不变
+func MaskSource(code string, syntax SourceSyntax, maskStrings bool) string {
+	masked := []byte(code)
+	blank := func(from, to int) {
+		for k := from; k < to; k++ {
+			if masked[k] != '\n' {
+				masked[k] = ' '
+			}
+		}
+	}
+	i := 0
+scan:
+	for i < len(code) {
+		for _, block := range syntax.blockComments {
+			if hasPrefixAt(code, i, block[0]) {
+				end := strings.Index(code[i+len(block[0]):], block[1])
+				if end < 0 {
+					end = len(code)
+				} else {
+					end += i + len(block[0]) + len(block[1])
+				}
+				blank(i, end)
+				i = end
+				continue scan
+			}
+		}
+		for _, comment := range syntax.lineComments {
+			if hasPrefixAt(code, i, comment) {
+				end := strings.IndexByte(code[i:], '\n')
+				if end < 0 {
+					end = len(code)
+				} else {
+					end += i
+				}
+				blank(i, end)
+				i = end
+				continue scan
+			}
+		}
+		quotes := append(append([]string{}, syntax.quotes...), syntax.rawQuotes...)
+		for n, quote := range quotes {
+			if !hasPrefixAt(code, i, quote) {
+				continue
+			}
+			raw := n >= len(syntax.quotes)
+			j := i + len(quote)
+			for j < len(code) && !hasPrefixAt(code, j, quote) {
+				if code[j] == '\\' && !raw {
+					j++
+				}
+				j++
+			}
+			if j > len(code) {
+				j = len(code)
+			}
+			if maskStrings {
+				blank(i+len(quote), j)
+			}
+			i = j + len(quote)
+			continue scan
+		}
+		i++
+	}
+	return string(masked)
+}
@@ -0,0 +1,285 @@
+package provider
+
+// Zygote Worker
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// 预热进程的控制管道：请求从3号文件描述符读入，回复写到4号文件描述符，每行一个JSON
+// 每个请求有两个回复：fork之后先回复子进程的pid，子进程结束后再回复运行结果，两个回复都带上请求的nonce
+const (
+	ZygoteRequestFd  = 3
+	ZygoteResponseFd = 4
+)
+
+// ZygoteRequest 请求预热进程运行一组测试数据，资源限制的含义和forkexec.ExecRLimit相同
+type ZygoteRequest struct {
+	Nonce         string `json:"nonce"`           // Random token echoed in the responses
+	Stdin         string `json:"stdin"`           // Input file path
+	Stdout        string `json:"stdout"`          // Output file path
+	Stderr        string `json:"stderr"`          // Error file path
+	TimeLimit     int    `json:"time_limit"`      // Time limit (ms)
+	RealTimeLimit int    `json:"real_time_limit"` // Real time limit (ms)
+	MemoryLimit   int    `json:"memory_limit"`    // Memory limit (KB)
+	FileSizeLimit int    `json:"file_size_limit"` // File size limit (bytes)
+}
+
+// ZygoteResponse 预热进程的回复，启动完成时回复一次ready，之后每个请求回复子进程的pid和运行结果
+type ZygoteResponse struct {
+	Ready      bool   `json:"ready"`   // Worker is ready (the first response)
+	Nonce      string `json:"nonce"`   // Nonce of the request
+	Pid        int    `json:"pid"`     // Pid of the forked child (the first response of a request)
+	Status     int    `json:"status"`  // Raw wait status of the child
+	UserTime   int64  `json:"utime"`   // User CPU time (us)
+	SystemTime int64  `json:"stime"`   // System CPU time (us)
+	Minflt     int64  `json:"minflt"`  // Minor page faults
+	MaxRSS     int64  `json:"maxrss"`  // Maximum resident set size (KB)
+	Startup    int64  `json:"startup"` // CPU time (us) used before running the solution (worker boot time for the ready response)
+	Error      string `json:"error"`   // Worker error
+}
+
+// WriteZygoteRequest 发送一个请求
+func WriteZygoteRequest(writer io.Writer, request *ZygoteRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(body, '\n'))
+	return err
+}
+
+// ReadZygoteResponse 读取一个回复，预热进程退出时返回io.EOF
+func ReadZygoteResponse(reader *bufio.Reader) (*ZygoteResponse, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && strings.TrimSpace(line) == "" {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	response := ZygoteResponse{}
+	if err = json.Unmarshal([]byte(line), &response); err != nil {
+		return nil, errors.Errorf("parse zygote response error: %s", err.Error())
+	}
+	if response.Error != "" {
+		return nil, errors.Errorf("zygote error: %s", response.Error)
+	}
+	return &response, nil
+}
+
+// 预热进程的脚本 (按语言family)
+var zygoteScripts = map[string]string{
+	"python3": pythonZygoteScript,
+	"python2": pythonZygoteScript,
+}
+
+// 预热进程脚本的文件名
+const zygoteScriptName = "deer_zygote.py"
+
+// python的预热进程：启动时载入常用模块并编译代码，每组测试数据fork一个子进程，
+// 子进程重定向输入输出、设置资源限制后，在新的__main__模块中运行代码 (兼容python2)
+const pythonZygoteScript = `import json
+import math
+import os
+import resource
+import signal
+import sys
+import traceback
+import types
+
+for _name in ("re", "collections", "itertools", "functools", "heapq", "bisect", "string", "random", "decimal", "fractions"):
+    try:
+        __import__(_name)
+    except ImportError:
+        pass
+
+REQUEST_FD = 3
+RESPONSE_FD = 4
+PR_SET_DUMPABLE = 4
+
+
+def protect_worker():
+    # 预热进程设置为不可dump，和子进程同一用户的进程都不能通过/proc/<pid>/fd或ptrace访问它的控制管道
+    if not sys.platform.startswith("linux"):
+        return
+    import ctypes
+    libc = ctypes.CDLL(None, use_errno=True)
+    if libc.prctl(PR_SET_DUMPABLE, 0, 0, 0, 0) != 0:
+        raise OSError(ctypes.get_errno(), "prctl(PR_SET_DUMPABLE) failed")
+
+
+def respond(responses, response):
+    responses.write(json.dumps(response) + "\n")
+    responses.flush()
+
+
+def cpu_us(usage):
+    return int((usage.ru_utime + usage.ru_stime) * 1000000)
+
+
+def redirect(file_name, fd, flags):
+    opened = os.open(file_name, flags, 420)
+    os.dup2(opened, fd)
+    os.close(opened)
+
+
+def set_limits(request):
+    time_limit = request.get("time_limit", 0)
+    if time_limit > 0:
+        seconds = int(math.ceil(time_limit / 1000.0))
+        resource.setrlimit(resource.RLIMIT_CPU, (seconds, seconds))
+    memory_limit = request.get("memory_limit", 0) * 1024
+    if memory_limit > 0:
+        resource.setrlimit(resource.RLIMIT_DATA, (memory_limit, memory_limit * 2))
+        resource.setrlimit(resource.RLIMIT_AS, (memory_limit * 2, memory_limit * 2 + 1024))
+        if sys.platform != "darwin":
+            resource.setrlimit(resource.RLIMIT_STACK, (memory_limit * 2, memory_limit * 2 + 1024))
+    file_size_limit = request.get("file_size_limit", 0)
+    if file_size_limit > 0:
+        resource.setrlimit(resource.RLIMIT_FSIZE, (file_size_limit, file_size_limit))
+    real_time_limit = request.get("real_time_limit", 0)
+    if real_time_limit > 0:
+        signal.setitimer(signal.ITIMER_REAL, real_time_limit / 1000.0, real_time_limit / 1000.0)
+
+
+def exit_code(error):
+    if error.code is None:
+        return 0
+    if isinstance(error.code, int):
+        return error.code
+    sys.stderr.write(str(error.code) + "\n")
+    return 1
+
+
+def run_child(request, source, code, compile_error, startup_fd):
+    status = 1
+    try:
+        os.close(REQUEST_FD)
+        os.close(RESPONSE_FD)
+        redirect(request["stdin"], 0, os.O_RDONLY)
+        redirect(request["stdout"], 1, os.O_WRONLY | os.O_CREAT | os.O_TRUNC)
+        redirect(request["stderr"], 2, os.O_WRONLY | os.O_CREAT | os.O_TRUNC)
+        set_limits(request)
+        os.write(startup_fd, str(cpu_us(resource.getrusage(resource.RUSAGE_SELF))).encode("ascii"))
+        os.close(startup_fd)
+        if compile_error is not None:
+            sys.stderr.write(compile_error)
+        else:
+            module = types.ModuleType("__main__")
+            module.__dict__["__file__"] = source
+            module.__dict__["__builtins__"] = __builtins__
+            sys.modules["__main__"] = module
+            sys.argv = [source]
+            sys.path[0] = os.path.dirname(source)
+            exec(code, module.__dict__)
+            status = 0
+    except SystemExit as error:
+        status = exit_code(error)
+    except BaseException:
+        traceback.print_exc()
+    try:
+        sys.stdout.flush()
+        sys.stderr.flush()
+    except BaseException:
+        pass
+    os._exit(status & 0xff)
+
+
+def run_case(request, source, code, compile_error, responses):
+    startup_read, startup_write = os.pipe()
+    pid = os.fork()
+    if pid == 0:
+        os.close(startup_read)
+        run_child(request, source, code, compile_error, startup_write)
+    os.close(startup_write)
+    # 评测端按真实时间限制计时，超时时直接结束子进程
+    respond(responses, {"nonce": request.get("nonce"), "pid": pid})
+    _, status, usage = os.wait4(pid, 0)
+    startup = os.read(startup_read, 64)
+    os.close(startup_read)
+    return {
+        "nonce": request.get("nonce"),
+        "status": status,
+        "utime": int(usage.ru_utime * 1000000),
+        "stime": int(usage.ru_stime * 1000000),
+        "minflt": usage.ru_minflt,
+        "maxrss": usage.ru_maxrss,
+        "startup": int(startup or 0),
+    }
+
+
+def main():
+    protect_worker()
+    source = os.path.abspath(sys.argv[1])
+    code, compile_error = None, None
+    try:
+        with open(source, "rb") as fp:
+            code = compile(fp.read(), source, "exec")
+    except Exception:
+        compile_error = traceback.format_exc()
+    requests = os.fdopen(REQUEST_FD, "r")
+    responses = os.fdopen(RESPONSE_FD, "w")
+    respond(responses, {"ready": True, "startup": cpu_us(resource.getrusage(resource.RUSAGE_SELF))})
+    while True:
+        line = requests.readline()
+        if not line:
+            break
+        request = json.loads(line)
+        try:
+            response = run_case(request, source, code, compile_error, responses)
+        except Exception as error:
+            response = {"nonce": request.get("nonce"), "error": str(error)}
+        respond(responses, response)
+
+
+main()
+`
+
+// 写入预热进程的脚本
+func writeZygoteScript(lang *LanguageDefinition, workDir string) (string, error) {
+	script, ok := zygoteScripts[GetLanguageFamily(lang.Name)]
+	if !ok {
+		return "", errors.Errorf("language (%s) has no bundled zygote worker", lang.Name)
+	}
+	scriptFile := path.Join(workDir, zygoteScriptName)
+	if err := ioutil.WriteFile(scriptFile, []byte(script), 0644); err != nil {
+		return "", err
+	}
+	return scriptFile, nil
+}
+
+// GetZygoteArgs 获取预热进程的启动参数，需要时会把预热进程的脚本写入工作目录 (语言不支持时返回nil)
+func (prov *GenericCompileProvider) GetZygoteArgs() ([]string, error) {
+	if prov.Language.ZygoteCommand.IsEmpty() {
+		return nil, nil
+	}
+	vars := prov.commandVariables()
+	for _, arg := range prov.Language.ZygoteCommand {
+		if strings.Contains(arg, "{zygote}") {
+			scriptFile, err := writeZygoteScript(prov.Language, prov.workDir)
+			if err != nil {
+				return nil, err
+			}
+			vars.Zygote = scriptFile
+			break
+		}
+	}
+	return prov.Language.ZygoteCommand.Render(vars), nil
+}
+
+// GetWarmupArgs 获取预热命令、使用预热结果的运行命令以及预热生成的缓存文件 (语言不支持时返回nil)
+func (prov *GenericCompileProvider) GetWarmupArgs() (warmup []string, run []string, cache string) {
+	lang := prov.Language
+	if lang.WarmupCommand.IsEmpty() || lang.WarmRunCommand.IsEmpty() || lang.WarmupCache == "" {
+		return nil, nil, ""
+	}
+	vars := prov.commandVariables()
+	return lang.WarmupCommand.Render(vars), lang.WarmRunCommand.Render(vars), CommandTemplate{lang.WarmupCache}.Render(vars)[0]
+}
@@ -0,0 +1,205 @@
+package similarity
+
+// Similarity Detection
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"sort"
+)
+
+// 默认的检测参数
+const (
+	DefaultKGram     = 10
+	DefaultWindow    = 5
+	DefaultThreshold = 0.5
+)
+
+// Options 相似度检测参数
+type Options struct {
+	KGram     int     `json:"kgram"`     // Tokens per fingerprint (noise threshold)
+	Window    int     `json:"window"`    // Winnowing window size
+	Threshold float64 `json:"threshold"` // Minimum similarity of reported pairs (0~1)
+}
+
+func (options Options) withDefault() Options {
+	if options.KGram <= 0 {
+		options.KGram = DefaultKGram
+	}
+	if options.Window <= 0 {
+		options.Window = DefaultWindow
+	}
+	return options
+}
+
+// Submission 参与检测的提交
+type Submission struct {
+	Name         string        `json:"name"`     // Submission name (file, directory or result package name)
+	Language     string        `json:"language"` // Language name
+	Code         string        `json:"-"`        // Source code (multiple files are joined)
+	Tokens       []Token       `json:"-"`
+	Fingerprints []Fingerprint `json:"-"`
+	TokenCount   int           `json:"tokens"` // Token count after normalization
+}
+
+// MatchedRegion 两份代码中相似的片段 (行号从1开始，包含两端)
+type MatchedRegion struct {
+	StartLineA int `json:"start_line_a"`
+	EndLineA   int `json:"end_line_a"`
+	StartLineB int `json:"start_line_b"`
+	EndLineB   int `json:"end_line_b"`
+	Tokens     int `json:"tokens"` // Matched tokens in A
+}
+
+// PairResult 两份提交的相似度
+type PairResult struct {
+	A          string          `json:"a"`
+	B          string          `json:"b"`
+	Language   string          `json:"language"`
+	Similarity float64         `json:"similarity"` // Average of PercentA and PercentB
+	PercentA   float64         `json:"percent_a"`  // Shared fingerprints / fingerprints of A
+	PercentB   float64         `json:"percent_b"`  // Shared fingerprints / fingerprints of B
+	Regions    []MatchedRegion `json:"regions"`
+}
+
+// Report 相似度检测结果
+type Report struct {
+	Options     Options       `json:"options"`
+	Submissions []*Submission `json:"submissions"`
+	Pairs       []PairResult  `json:"pairs"` // Sorted by similarity (DESC)
+}
+
+// NewSubmission 规范化代码并计算指纹
+func NewSubmission(name string, langName string, code string, options Options) *Submission {
+	options = options.withDefault()
+	tokens := Tokenize(code, langName)
+	return &Submission{
+		Name:         name,
+		Language:     langName,
+		Code:         code,
+		Tokens:       tokens,
+		Fingerprints: Winnow(tokens, options.KGram, options.Window),
+		TokenCount:   len(tokens),
+	}
+}
+
+// 按哈希值索引指纹，忽略模板代码中出现的指纹
+func indexFingerprints(sub *Submission, base map[uint64]bool) map[uint64][]Fingerprint {
+	index := map[uint64][]Fingerprint{}
+	for _, fp := range sub.Fingerprints {
+		if !base[fp.Hash] {
+			index[fp.Hash] = append(index[fp.Hash], fp)
+		}
+	}
+	return index
+}
+
+// 统计共同的指纹占比
+func sharedPercent(fingerprints []Fingerprint, other map[uint64][]Fingerprint, base map[uint64]bool) float64 {
+	total, shared := 0, 0
+	for _, fp := range fingerprints {
+		if base[fp.Hash] {
+			continue
+		}
+		total++
+		if _, ok := other[fp.Hash]; ok {
+			shared++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(shared) / float64(total)
+}
+
+// ComparePair 比较两份提交，gap为合并相似片段时允许的间隔(记号数)
+func ComparePair(a, b *Submission, base map[uint64]bool, gap int) PairResult {
+	indexB := indexFingerprints(b, base)
+	indexA := indexFingerprints(a, base)
+	result := PairResult{
+		A:        a.Name,
+		B:        b.Name,
+		Language: a.Language,
+		PercentA: sharedPercent(a.Fingerprints, indexB, base),
+		PercentB: sharedPercent(b.Fingerprints, indexA, base),
+		Regions:  make([]MatchedRegion, 0),
+	}
+	result.Similarity = (result.PercentA + result.PercentB) / 2
+
+	// 按A中的顺序合并相邻的相同指纹，得到相似的片段 (以记号的下标表示)
+	type span struct{ aStart, aEnd, bStart, bEnd int }
+	spans := make([]span, 0)
+	for _, fp := range a.Fingerprints {
+		matches, ok := indexB[fp.Hash]
+		if !ok || base[fp.Hash] {
+			continue
+		}
+		// 优先选择能接上上一个片段的位置
+		match := matches[0]
+		if len(spans) > 0 {
+			last := spans[len(spans)-1]
+			for _, m := range matches {
+				if m.Start >= last.bStart && m.Start <= last.bEnd+gap+1 {
+					match = m
+					break
+				}
+			}
+		}
+		if len(spans) > 0 {
+			last := &spans[len(spans)-1]
+			if fp.Start <= last.aEnd+gap+1 && match.Start >= last.bStart && match.Start <= last.bEnd+gap+1 {
+				if fp.End > last.aEnd {
+					last.aEnd = fp.End
+				}
+				if match.End > last.bEnd {
+					last.bEnd = match.End
+				}
+				continue
+			}
+		}
+		spans = append(spans, span{fp.Start, fp.End, match.Start, match.End})
+	}
+	for _, s := range spans {
+		result.Regions = append(result.Regions, MatchedRegion{
+			StartLineA: a.Tokens[s.aStart].Line,
+			EndLineA:   a.Tokens[s.aEnd].Line,
+			StartLineB: b.Tokens[s.bStart].Line,
+			EndLineB:   b.Tokens[s.bEnd].Line,
+			Tokens:     s.aEnd - s.aStart + 1,
+		})
+	}
+	return result
+}
+
+// Detect 两两比较同一语言(family)的提交，返回相似度不低于阈值的结果
+// bases为模板代码(如题目提供的框架代码)，其中出现的指纹不计入相似度
+func Detect(submissions []*Submission, bases []*Submission, options Options) *Report {
+	options = options.withDefault()
+	base := map[string]map[uint64]bool{}
+	for _, sub := range bases {
+		family := provider.GetLanguageFamily(sub.Language)
+		if base[family] == nil {
+			base[family] = map[uint64]bool{}
+		}
+		for _, fp := range sub.Fingerprints {
+			base[family][fp.Hash] = true
+		}
+	}
+	report := &Report{Options: options, Submissions: submissions, Pairs: make([]PairResult, 0)}
+	for i := 0; i < len(submissions); i++ {
+		for j := i + 1; j < len(submissions); j++ {
+			a, b := submissions[i], submissions[j]
+			family := provider.GetLanguageFamily(a.Language)
+			if family != provider.GetLanguageFamily(b.Language) {
+				continue
+			}
+			pair := ComparePair(a, b, base[family], options.Window)
+			if pair.Similarity >= options.Threshold && pair.Similarity > 0 {
+				report.Pairs = append(report.Pairs, pair)
+			}
+		}
+	}
+	sort.SliceStable(report.Pairs, func(i, j int) bool {
+		return report.Pairs[i].Similarity > report.Pairs[j].Similarity
+	})
+	return report
+}
@@ -0,0 +1,130 @@
+package similarity
+
+// Similarity Report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// 报告中每一行代码的展示信息
+type reportLine struct {
+	No     int
+	Text   string
+	Region int // 所在的相似片段的下标，-1表示不在相似片段中
+}
+
+type reportPair struct {
+	ID         int
+	A, B       string
+	Language   string
+	Similarity string
+	PercentA   string
+	PercentB   string
+	Regions    []MatchedRegion
+	LinesA     []reportLine
+	LinesB     []reportLine
+}
+
+// 标记每一行所在的相似片段
+func markLines(code string, regions []MatchedRegion, isA bool) []reportLine {
+	texts := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	lines := make([]reportLine, len(texts))
+	for i, text := range texts {
+		lines[i] = reportLine{No: i + 1, Text: text, Region: -1}
+	}
+	for k, region := range regions {
+		start, end := region.StartLineA, region.EndLineA
+		if !isA {
+			start, end = region.StartLineB, region.EndLineB
+		}
+		for no := start; no <= end && no <= len(lines); no++ {
+			if lines[no-1].Region < 0 {
+				lines[no-1].Region = k
+			}
+		}
+	}
+	return lines
+}
+
+func percent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value*100)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"color": func(region int) int { return region % 6 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Similarity Report</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table.summary { border-collapse: collapse; }
+table.summary td, table.summary th { border: 1px solid #ccc; padding: 4px 8px; }
+.pair { margin-top: 32px; }
+.code { display: flex; gap: 16px; }
+.code > div { flex: 1; overflow-x: auto; }
+pre { margin: 0; font-size: 12px; }
+.ln { color: #999; display: inline-block; width: 40px; text-align: right; margin-right: 8px; }
+.m0 { background: #ffe0e0; } .m1 { background: #e0ffe0; } .m2 { background: #e0e8ff; }
+.m3 { background: #fff4c0; } .m4 { background: #f0e0ff; } .m5 { background: #d0f4f4; }
+</style>
+</head>
+<body>
+<h1>Similarity Report</h1>
+<p>k-gram: {{.Options.KGram}}, window: {{.Options.Window}}, threshold: {{.Threshold}}, submissions: {{.Total}}</p>
+{{if .Pairs}}
+<table class="summary">
+<tr><th>A</th><th>B</th><th>Language</th><th>Similarity</th><th>A%</th><th>B%</th><th>Regions</th></tr>
+{{range .Pairs}}<tr><td>{{.A}}</td><td>{{.B}}</td><td>{{.Language}}</td><td><a href="#pair-{{.ID}}">{{.Similarity}}</a></td><td>{{.PercentA}}</td><td>{{.PercentB}}</td><td>{{len .Regions}}</td></tr>
+{{end}}</table>
+{{range .Pairs}}
+<div class="pair" id="pair-{{.ID}}">
+<h2>{{.A}} &harr; {{.B}} ({{.Similarity}})</h2>
+<ul>{{range .Regions}}<li>A: {{.StartLineA}}-{{.EndLineA}}, B: {{.StartLineB}}-{{.EndLineB}} ({{.Tokens}} tokens)</li>{{end}}</ul>
+<div class="code">
+<div><h3>{{.A}}</h3><pre>{{range .LinesA}}<span{{if ge .Region 0}} class="m{{color .Region}}"{{end}}><span class="ln">{{.No}}</span>{{.Text}}</span>
+{{end}}</pre></div>
+<div><h3>{{.B}}</h3><pre>{{range .LinesB}}<span{{if ge .Region 0}} class="m{{color .Region}}"{{end}}><span class="ln">{{.No}}</span>{{.Text}}</span>
+{{end}}</pre></div>
+</div>
+</div>
+{{end}}
+{{else}}
+<p>No similar submissions found.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML 输出HTML格式的报告，相似片段按颜色对应标出 (不依赖外部资源，可以离线查看)
+func (report *Report) WriteHTML(writer io.Writer) error {
+	codes := map[string]string{}
+	for _, sub := range report.Submissions {
+		codes[sub.Name] = sub.Code
+	}
+	pairs := make([]reportPair, 0, len(report.Pairs))
+	for i, pair := range report.Pairs {
+		pairs = append(pairs, reportPair{
+			ID:         i + 1,
+			A:          pair.A,
+			B:          pair.B,
+			Language:   pair.Language,
+			Similarity: percent(pair.Similarity),
+			PercentA:   percent(pair.PercentA),
+			PercentB:   percent(pair.PercentB),
+			Regions:    pair.Regions,
+			LinesA:     markLines(codes[pair.A], pair.Regions, true),
+			LinesB:     markLines(codes[pair.B], pair.Regions, false),
+		})
+	}
+	return reportTemplate.Execute(writer, map[string]interface{}{
+		"Options":   report.Options,
+		"Threshold": percent(report.Options.Threshold),
+		"Total":     len(report.Submissions),
+		"Pairs":     pairs,
+	})
+}
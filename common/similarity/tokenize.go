@@ -0,0 +1,138 @@
+package similarity
+
+// Source Normalization
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+)
+
+// Token 规范化后的记号
+type Token struct {
+	Text string // 规范化后的内容：关键字和符号保持不变，标识符为V，数字为N
+	Line int    // 所在的行号 (从1开始)
+}
+
+// 各语言的关键字 (按family)，不在其中的标识符都会被重命名，使得修改变量名、函数名不影响结果
+var languageKeywords = map[string][]string{
+	"gcc": {
+		"auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else", "enum", "extern",
+		"float", "for", "goto", "if", "inline", "int", "long", "register", "return", "short", "signed", "sizeof",
+		"static", "struct", "switch", "typedef", "union", "unsigned", "void", "volatile", "while", "include", "define",
+	},
+	"g++": {
+		"auto", "bool", "break", "case", "catch", "char", "class", "const", "constexpr", "continue", "default",
+		"delete", "do", "double", "else", "enum", "explicit", "extern", "false", "float", "for", "friend", "goto",
+		"if", "inline", "int", "long", "namespace", "new", "nullptr", "operator", "private", "protected", "public",
+		"return", "short", "signed", "sizeof", "static", "struct", "switch", "template", "this", "throw", "true",
+		"try", "typedef", "typename", "union", "unsigned", "using", "virtual", "void", "volatile", "while",
+		"include", "define",
+	},
+	"java": {
+		"abstract", "boolean", "break", "byte", "case", "catch", "char", "class", "continue", "default", "do",
+		"double", "else", "extends", "false", "final", "finally", "float", "for", "if", "implements", "import",
+		"instanceof", "int", "interface", "long", "new", "null", "package", "private", "protected", "public",
+		"return", "short", "static", "super", "switch", "this", "throw", "throws", "true", "try", "void", "while",
+	},
+	"csharp": {
+		"bool", "break", "case", "catch", "char", "class", "const", "continue", "default", "do", "double", "else",
+		"false", "float", "for", "foreach", "if", "in", "int", "long", "namespace", "new", "null", "private",
+		"public", "return", "static", "string", "struct", "switch", "this", "true", "try", "using", "var", "void", "while",
+	},
+	"kotlin": {
+		"break", "class", "continue", "do", "else", "false", "for", "fun", "if", "in", "is", "null", "object",
+		"return", "this", "true", "val", "var", "when", "while",
+	},
+	"golang": {
+		"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+		"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var", "nil", "true", "false",
+	},
+	"rust": {
+		"as", "break", "const", "continue", "else", "enum", "false", "fn", "for", "if", "impl", "in", "let",
+		"loop", "match", "mod", "move", "mut", "pub", "ref", "return", "self", "static", "struct", "trait",
+		"true", "type", "use", "where", "while",
+	},
+	"python3": {
+		"and", "as", "assert", "break", "class", "continue", "def", "del", "elif", "else", "except", "False",
+		"finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "None", "nonlocal", "not",
+		"or", "pass", "raise", "return", "True", "try", "while", "with", "yield",
+	},
+	"python2": {
+		"and", "as", "assert", "break", "class", "continue", "def", "del", "elif", "else", "except", "exec",
+		"finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "not", "or", "pass", "print",
+		"raise", "return", "try", "while", "with", "yield", "None", "True", "False",
+	},
+	"nodejs": {
+		"break", "case", "catch", "class", "const", "continue", "default", "delete", "do", "else", "false",
+		"for", "function", "if", "in", "let", "new", "null", "of", "return", "switch", "this", "throw", "true",
+		"try", "typeof", "undefined", "var", "while",
+	},
+	"php": {
+		"array", "as", "break", "case", "class", "continue", "default", "do", "echo", "else", "elseif",
+		"false", "for", "foreach", "function", "if", "new", "null", "return", "switch", "true", "while",
+	},
+	"ruby": {
+		"and", "begin", "break", "case", "class", "def", "do", "else", "elsif", "end", "false", "for", "if",
+		"in", "module", "next", "nil", "not", "or", "return", "self", "then", "true", "unless", "until",
+		"when", "while", "yield",
+	},
+}
+
+// 获取关键字集合，没有定义关键字的语言使用全部语言关键字的并集
+func getKeywordSet(family string) map[string]bool {
+	set := map[string]bool{}
+	if keywords, ok := languageKeywords[family]; ok {
+		for _, keyword := range keywords {
+			set[keyword] = true
+		}
+		return set
+	}
+	for _, keywords := range languageKeywords {
+		for _, keyword := range keywords {
+			set[keyword] = true
+		}
+	}
+	return set
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Tokenize 按语言规范化代码：去掉注释和字符串的内容、重命名标识符，再拆分为记号
+func Tokenize(code string, langName string) []Token {
+	if syntax, ok := provider.GetSourceSyntax(langName); ok {
+		code = provider.MaskSource(code, syntax, true)
+	}
+	keywords := getKeywordSet(provider.GetLanguageFamily(langName))
+	tokens := make([]Token, 0)
+	line := 1
+	for i := 0; i < len(code); {
+		c := code[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case isWordByte(c):
+			j := i
+			for j < len(code) && isWordByte(code[j]) {
+				j++
+			}
+			word := code[i:j]
+			switch {
+			case c >= '0' && c <= '9':
+				word = "N"
+			case !keywords[word]:
+				word = "V"
+			}
+			tokens = append(tokens, Token{Text: word, Line: line})
+			i = j
+		default:
+			tokens = append(tokens, Token{Text: string(c), Line: line})
+			i++
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,58 @@
+package similarity
+
+// Winnowing Fingerprints
+
+import (
+	"hash/fnv"
+)
+
+// Fingerprint 代码指纹，为k个连续记号(k-gram)的哈希值
+type Fingerprint struct {
+	Hash  uint64 // Hash of the k-gram
+	Start int    // First token index
+	End   int    // Last token index
+}
+
+// 计算每个k-gram的哈希值
+func kGramHashes(tokens []Token, k int) []uint64 {
+	if len(tokens) < k {
+		return nil
+	}
+	hashes := make([]uint64, 0, len(tokens)-k+1)
+	for i := 0; i+k <= len(tokens); i++ {
+		hash := fnv.New64a()
+		for _, token := range tokens[i : i+k] {
+			_, _ = hash.Write([]byte(token.Text))
+			_, _ = hash.Write([]byte{0})
+		}
+		hashes = append(hashes, hash.Sum64())
+	}
+	return hashes
+}
+
+// Winnow 按winnowing算法选取指纹：在每个长度为window的窗口中选择最小的哈希值(相同时取最右边的)
+// 长度不小于k+window-1的相同片段一定会产生相同的指纹，短于k的片段不会被检测
+func Winnow(tokens []Token, k int, window int) []Fingerprint {
+	hashes := kGramHashes(tokens, k)
+	if len(hashes) == 0 {
+		return nil
+	}
+	if window > len(hashes) {
+		window = len(hashes)
+	}
+	fingerprints := make([]Fingerprint, 0)
+	selected := -1
+	for i := 0; i+window <= len(hashes); i++ {
+		min := i
+		for j := i; j < i+window; j++ {
+			if hashes[j] <= hashes[min] {
+				min = j
+			}
+		}
+		if min != selected {
+			fingerprints = append(fingerprints, Fingerprint{Hash: hashes[min], Start: min, End: min + k - 1})
+			selected = min
+		}
+	}
+	return fingerprints
+}
@@ -14,21 +14,106 @@ type JudgeConfiguration struct {
 	FileSizeLimit int                           `json:"file_size_limit"` // File Size Limit (bytes) (optional)
 	UID           int                           `json:"uid"`             // User id (optional)
 	StrictMode    bool                          `json:"strict_mode"`     // Strict Mode (if close, PE will be ignore)
+	StreamCompare bool                          `json:"stream_compare"`  // Compare output while running, abort at a definite WA or OLE (optional)
 	SpecialJudge  SpecialJudgeOptions           `json:"special_judge"`   // Special Judge Options
 	Limitation    map[string]JudgeResourceLimit `json:"limitation"`      // Limitation
 	Problem       ProblemContent                `json:"problem"`         // Problem Info
 	TestLib       TestlibOptions                `json:"testlib"`         // testlib设置
 	AnswerCases   []AnswerCase                  `json:"answer_cases"`    // Answer cases (用于生成Output)
+	Project       ProjectOptions                `json:"project"`         // Multi-file submission options
+	Graders       map[string]GraderOptions      `json:"graders"`         // Graders for function implementation problems, key is language name or family
+	UnitTest      UnitTestOptions               `json:"unit_test"`       // Unit-test problem options (harness files are set by graders)
+	SQL           SQLOptions                    `json:"sql"`             // SQL problem options
+	Lint          map[string]LintOptions        `json:"lint"`            // Forbidden-construct checks before compiling, key is language name, family or "*"
+	Timeouts      TimeoutOptions                `json:"timeouts"`        // Hard timeouts of each phase and the total judge budget (optional, use the system config when not set)
 	ConfigDir     string                        `json:"-"`               // 内部字段：config文件所在目录绝对路径
 }
 
+// TimeoutOptions 各阶段的硬超时(墙钟时间)设置，单位为ms，0表示未设置
+type TimeoutOptions struct {
+	Compile    int `json:"compile"`    // Compile timeout, caps the compile limits of the language
+	Run        int `json:"run"`        // Target program (and answer program) timeout
+	Checker    int `json:"checker"`    // Checker timeout
+	Interactor int `json:"interactor"` // Interactor timeout (interactive and communication mode)
+	Generator  int `json:"generator"`  // Generator timeout (time_limit of the generator takes precedence)
+	Validator  int `json:"validator"`  // Validator timeout
+	Total      int `json:"total"`      // Total judge budget, the judgement is aborted when exceeded
+}
+
+// Merge 使用defaults填充没有设置的项
+func (opts TimeoutOptions) Merge(defaults TimeoutOptions) TimeoutOptions {
+	if opts.Compile <= 0 {
+		opts.Compile = defaults.Compile
+	}
+	if opts.Run <= 0 {
+		opts.Run = defaults.Run
+	}
+	if opts.Checker <= 0 {
+		opts.Checker = defaults.Checker
+	}
+	if opts.Interactor <= 0 {
+		opts.Interactor = defaults.Interactor
+	}
+	if opts.Generator <= 0 {
+		opts.Generator = defaults.Generator
+	}
+	if opts.Validator <= 0 {
+		opts.Validator = defaults.Validator
+	}
+	if opts.Total <= 0 {
+		opts.Total = defaults.Total
+	}
+	return opts
+}
+
 // AnswerCase 答案代码样例
 // 优先使用Content访问，其次使用FileName
 type AnswerCase struct {
-	Name     string `json:"name"`      // Case name
-	FileName string `json:"file_name"` // code file name
-	Language string `json:"language"`  // code language, default is 'auto'
-	Content  string `json:"content"`   // code content (optional)
+	Name            string `json:"name"`             // Case name
+	FileName        string `json:"file_name"`        // code file name
+	Language        string `json:"language"`         // code language, default is 'auto'
+	Content         string `json:"content"`          // code content (optional)
+	ExpectedVerdict string `json:"expected_verdict"` // Expected verdict: main|ac|wa|tle|tle_or_ac|re, default is 'ac'
+}
+
+// ProjectOptions 多文件提交(代码包或目录)设置
+type ProjectOptions struct {
+	Entry       string   `json:"entry"`        // Entry file, relative to the project root (optional, detected by language)
+	BuildScript string   `json:"build_script"` // Custom build script, relative to the config dir, run by /bin/sh in the project root (optional)
+	RunCommand  []string `json:"run_command"`  // Run command after the custom build, supports {dir} (required when build_script is set)
+}
+
+// GraderOptions 函数实现题的grader设置
+// grader文件和提交的代码会放在同一个目录下一起编译，grader负责输入输出，提交的代码只需要实现函数
+type GraderOptions struct {
+	Files          []string `json:"files"`           // Grader source files and headers, relative to the config dir
+	Entry          string   `json:"entry"`           // Entry file name (optional, default is the first file)
+	SubmissionName string   `json:"submission_name"` // File name of the submission, default is "solution" + extension (Java: "<class name>.java")
+}
+
+// UnitTestOptions 单元测试模式设置
+type UnitTestOptions struct {
+	ReportFormat string         `json:"report_format"` // Report format: junit|json, default is junit
+	ReportFile   string         `json:"report_file"`   // Report file written by the harness, relative to its working dir; empty means stdout
+	Weights      map[string]int `json:"weights"`       // Score weight per test, key is "<class>.<name>" or "<name>", default is 1
+}
+
+// SQLOptions SQL题设置
+// 测试数据的input为建表和插入数据的脚本，output为期望的查询结果 (带表头的CSV，即sqlite3 -header -csv的输出)
+type SQLOptions struct {
+	Engine           string  `json:"engine"`             // SQLite command line program, default is "sqlite3"
+	Ordered          bool    `json:"ordered"`            // Compare rows in order (for queries with ORDER BY)
+	CheckColumnNames bool    `json:"check_column_names"` // Column names must be the same as the expected ones
+	FloatTolerance   float64 `json:"float_tolerance"`    // Absolute or relative tolerance of numeric values, 0 means exact text
+}
+
+// LintOptions 编译前的静态检查规则，检查时会忽略注释和字符串 (C/C++、Java、Go、Python等)
+// 违反规则时判为Forbidden Code，不再编译运行
+type LintOptions struct {
+	Patterns         []string `json:"patterns"`          // Forbidden regular expressions (e.g. "system\\s*\\(")
+	Keywords         []string `json:"keywords"`          // Forbidden tokens or keywords (e.g. "goto", "system")
+	AllowedImports   []string `json:"allowed_imports"`   // Header/import whitelist, empty means no restriction (e.g. "stdio.h", "java.util.*")
+	ForbiddenImports []string `json:"forbidden_imports"` // Header/import blacklist (e.g. "algorithm", "os")
 }
 
 // TestCase 测试数据
@@ -36,6 +121,7 @@ type TestCase struct {
 	Handle           string `json:"handle"`            // Identifier
 	Order            int    `json:"order"`             // Order (ASC)
 	Name             string `json:"name"`              // Testcase name
+	Group            string `json:"group"`             // Testcase group (subtask), passed to testlib validator as '--group'
 	Input            string `json:"input"`             // Testcase input file path
 	Output           string `json:"output"`            // Testcase output file path
 	Visible          bool   `json:"visible"`           // Is visible(for oj)
@@ -49,7 +135,7 @@ type TestCase struct {
 // SpecialJudgeOptions 特殊评测设置
 type SpecialJudgeOptions struct {
 	Name               string                    `json:"name"`                 // Name, default is "checker"
-	Mode               int                       `json:"mode"`                 // Mode；0-Disabled；1-Normal；2-Interactor
+	Mode               int                       `json:"mode"`                 // Mode；0-Disabled；1-Normal；2-Interactor；3-Communication
 	CheckerLang        string                    `json:"checker_lang"`         // Checker languages, support gcc, g++(default) and golang, not support auto!
 	Checker            string                    `json:"checker"`              // Checker file path (Use code file is better then compiled binary!)
 	RedirectProgramOut bool                      `json:"redirect_program_out"` // Redirect target program's STDOUT to checker's STDIN (checker mode). if not, redirect testcase-in file to checker's STDIN
@@ -57,6 +143,21 @@ type SpecialJudgeOptions struct {
 	MemoryLimit        int                       `json:"memory_limit"`         // Memory limit (kb)
 	UseTestlib         bool                      `json:"use_testlib"`          // If use testlib, checker will only support c++
 	CheckerCases       []SpecialJudgeCheckerCase `json:"checker_cases"`        // Special Judge checker cases (for Testlib, exclude interactor mode)
+	Communication      CommunicationOptions      `json:"communication"`        // Communication mode options (mode 3)
+}
+
+// CommunicationOptions 通信题设置
+// 选手程序会运行多个实例，由manager(即checker)转发消息，实例之间的管道连接方式由channels决定
+type CommunicationOptions struct {
+	Instances int                    `json:"instances"` // Number of contestant program instances, default is the length of roles or 2
+	Roles     []string               `json:"roles"`     // Instance names, passed to each instance as the last argument (optional)
+	Channels  []CommunicationChannel `json:"channels"`  // Pipes between manager and instances, default is a pair of pipes between manager and every instance
+}
+
+// CommunicationChannel 通信题的单向管道
+type CommunicationChannel struct {
+	From string `json:"from"` // Writer: "manager" or an instance name
+	To   string `json:"to"`   // Reader: "manager" or an instance name
 }
 
 // SpecialJudgeCheckerCase 特判检查器样例
@@ -74,18 +175,21 @@ type SpecialJudgeCheckerCase struct {
 // TestlibOptions TestLib设置 (只支持c++版本的testlib)
 // Testlib Options (we only support c++ verion)
 type TestlibOptions struct {
-	Version        string                 `json:"version"`        // Testlib version (预留，不太考虑实现)
-	Validator      string                 `json:"validator"`      // Validator file
-	ValidatorName  string                 `json:"validator_name"` // Validator name (compile target name)
-	Generators     []TestlibGenerator     `json:"generators"`     // Validator cases
-	ValidatorCases []TestlibValidatorCase `json:"validator_case"` // Validator cases
-	// 未来这边可以加入对拍(stress)功能
+	Version           string                 `json:"version"`            // Testlib version (预留，不太考虑实现)
+	Validator         string                 `json:"validator"`          // Validator file
+	ValidatorName     string                 `json:"validator_name"`     // Validator name (compile target name)
+	ValidatorLanguage string                 `json:"validator_language"` // Validator language, default is g++ (auto detect by file extension)
+	Generators        []TestlibGenerator     `json:"generators"`         // Validator cases
+	GeneratorScript   string                 `json:"generator_script"`   // Generator script file (Polygon-style, optional)
+	ValidatorCases    []TestlibValidatorCase `json:"validator_case"`     // Validator cases
 }
 
 // TestlibGenerator Testlib Generator
 type TestlibGenerator struct {
-	Name   string `json:"name"`   // Generator name
-	Source string `json:"source"` // Source code file
+	Name      string `json:"name"`       // Generator name
+	Source    string `json:"source"`     // Source code file
+	Language  string `json:"language"`   // Code language, default is g++ (auto detect by file extension)
+	TimeLimit int    `json:"time_limit"` // Time limit (ms), default is 3000
 }
 
 // TestlibValidatorCase Testlib validator 样例
@@ -103,13 +207,28 @@ type JudgeResult struct {
 	JudgeResult int                   `json:"judge_result"` // Judge result flag number
 	TimeUsed    int                   `json:"time_used"`    // Maximum time used
 	MemoryUsed  int                   `json:"memory_used"`  // Maximum memory used
+	StartupTime int                   `json:"startup_time"` // Warm worker startup time (ms), not counted in time_used (zygote mode)
 	TestCases   []TestCaseResult      `json:"test_cases"`   // Testcase Results
 	ReInfo      string                `json:"re_info"`      // ReInfo when Runtime Error or special judge Runtime Error
 	SeInfo      string                `json:"se_info"`      // SeInfo when System Error
 	CeInfo      string                `json:"ce_info"`      // CeInfo when Compile Error
+	LintInfo    string                `json:"lint_info"`    // LintInfo when Forbidden Code (rule and offending line)
+	Diagnostics []CompileDiagnostic   `json:"diagnostics"`  // Compiler warnings and errors (also kept when compiled successfully)
+	Score       int                   `json:"score"`        // Score of passed tests (unit test mode)
+	FullScore   int                   `json:"full_score"`   // Full score (unit test mode)
+	Aborted     bool                  `json:"aborted"`      // Aborted when the total judge budget is exceeded, test_cases is partial
 	JudgeLogs   []logger.JudgeLogItem `json:"judge_logs"`   // Judge Logs
 }
 
+// CompileDiagnostic 编译器输出的诊断信息 (警告、错误)，文件名已替换为中性的名称(如main.cpp)
+type CompileDiagnostic struct {
+	File     string `json:"file"`     // Source file name
+	Line     int    `json:"line"`     // Line number
+	Column   int    `json:"column"`   // Column number (0 if unknown)
+	Severity string `json:"severity"` // error, warning or note
+	Message  string `json:"message"`  // Diagnostic message
+}
+
 // TestCaseResult 测试数据运行结果
 type TestCaseResult struct {
 	Handle       string `json:"handle"`        // Identifier
@@ -128,6 +247,7 @@ type TestCaseResult struct {
 	TextDiffLog string `json:"text_diff_log"` // Text Checkup Log
 	TimeUsed    int    `json:"time_used"`     // Maximum time used
 	MemoryUsed  int    `json:"memory_used"`   // Maximum memory used
+	StartupTime int    `json:"startup_time"`  // CPU time (ms) to start the solution in a warm worker, not counted in time_used (zygote mode)
 	ReSignum    int    `json:"re_signal_num"` // Runtime error signal number
 	SameLines   int    `json:"same_lines"`    // Same lines when WA
 	TotalLines  int    `json:"total_lines"`   // Total lines when WA
@@ -140,6 +260,9 @@ type TestCaseResult struct {
 	SPJMemoryUsed int    `json:"spj_memory_used"`   // Special judge maximum memory used
 	SPJReSignum   int    `json:"spj_re_signal_num"` // Special judge runtime error signal number
 	SPJMsg        string `json:"spj_msg"`           // Special judge checker  msg
+
+	Weight  int    `json:"weight"`  // Score weight (unit test mode)
+	Message string `json:"message"` // Failure message (unit test mode)
 }
 
 // JudgeResourceLimit 评测资源限制信息
@@ -8,22 +8,27 @@ type ProblemIOSample struct {
 
 // ProblemContent 题目正文信息  (for oj)
 type ProblemContent struct {
-	Author      string                   `json:"author"`       // Problem author
-	Source      string                   `json:"source"`       // Problem source
-	Description string                   `json:"description"`  // Description
-	Input       string                   `json:"input"`        // Input requirements
-	Output      string                   `json:"output"`       // Output requirements
-	Sample      []ProblemIOSample        `json:"sample"`       // Sample cases
-	Tips        string                   `json:"tips"`         // Solution tips
-	ProblemType int                      `json:"problem_type"` // 题目类型
-	DemoCases   map[string]JudgeDemoCase `json:"demo_cases"`   // 代码填空样例数据
+	Author        string                   `json:"author"`         // Problem author
+	Source        string                   `json:"source"`         // Problem source
+	Description   string                   `json:"description"`    // Description
+	Input         string                   `json:"input"`          // Input requirements
+	Output        string                   `json:"output"`         // Output requirements
+	Sample        []ProblemIOSample        `json:"sample"`         // Sample cases
+	Tips          string                   `json:"tips"`           // Solution tips
+	ProblemType   int                      `json:"problem_type"`   // 题目类型 (0-普通；1-代码填空；2-单元测试；3-提交答案；4-SQL)
+	DemoCases     map[string]JudgeDemoCase `json:"demo_cases"`     // 代码填空样例数据
+	CodeTemplates map[string]string        `json:"code_templates"` // 代码填空的代码模板文件，key为语言名称或family
 }
 
 // JudgeDemoCase 代码填空样例 (for oj)
 type JudgeDemoCase struct {
-	Handle  string            `json:"handle"`  // handle
-	Name    string            `json:"name"`    // 代码区域名称
-	Answers map[string]string `json:"answers"` // 回答信息
-	Demo    string            `json:"demo"`    // 样例代码（预设用）
-	Line    int               `json:"line"`    // 插入位置
+	Handle          string            `json:"handle"`           // handle
+	Name            string            `json:"name"`             // 代码区域名称
+	Answers         map[string]string `json:"answers"`          // 回答信息
+	Demo            string            `json:"demo"`             // 样例代码（预设用）
+	Line            int               `json:"line"`             // 插入位置 (插入到模板的第几行之后，0为开头)
+	Lines           map[string]int    `json:"lines"`            // 各个语言的插入位置 (可选，key为语言名称或family)
+	Order           int               `json:"order"`            // 同一插入位置的多个空的顺序 (从小到大)
+	MaxLength       int               `json:"max_length"`       // 回答的最大长度 (bytes, 0为不限制)
+	ForbiddenTokens []string          `json:"forbidden_tokens"` // 回答中禁止使用的关键字
 }
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxArchiveFiles 解压提交的代码包时允许的最大文件数
+var MaxArchiveFiles = 1000
+
+// MaxArchiveSize 解压提交的代码包时允许的最大总大小 (bytes)
+var MaxArchiveSize int64 = 64 * 1024 * 1024
+
+// 解压时的计数器，防止压缩包炸弹
+type archiveCounter struct {
+	files int
+	size  int64
+}
+
+func (counter *archiveCounter) add(size int64) error {
+	counter.files++
+	counter.size += size
+	if counter.files > MaxArchiveFiles {
+		return errors.Errorf("too many files in archive (limit %d)", MaxArchiveFiles)
+	}
+	if counter.size > MaxArchiveSize {
+		return errors.Errorf("archive is too large (limit %d bytes)", MaxArchiveSize)
+	}
+	return nil
+}
+
+// IsArchiveFile 按扩展名判断是否为支持的压缩包 (.zip, .tar, .tar.gz, .tgz)
+func IsArchiveFile(fileName string) bool {
+	name := strings.ToLower(fileName)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// SafeJoin 拼接路径，结果不在root目录下时返回错误 (防止zip-slip)
+func SafeJoin(root string, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", errors.Errorf("illegal file path: %s", name)
+	}
+	target := filepath.Join(root, name)
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("illegal file path: %s", name)
+	}
+	return target, nil
+}
+
+// 把reader的内容写入文件，最多写入limit个字节
+func writeArchiveFile(target string, reader io.Reader, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	size, err := io.Copy(file, io.LimitReader(reader, limit+1))
+	if err != nil {
+		return size, err
+	}
+	if size > limit {
+		return size, errors.Errorf("archive is too large (limit %d bytes)", MaxArchiveSize)
+	}
+	return size, nil
+}
+
+// 解压zip文件
+func extractZip(archiveFile string, destDir string) error {
+	reader, err := zip.OpenReader(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	counter := archiveCounter{}
+	for _, f := range reader.File {
+		target, err := SafeJoin(destDir, f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.FileInfo().Mode()
+		if mode.IsDir() {
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		// 不解压符号链接等特殊文件
+		if !mode.IsRegular() {
+			return errors.Errorf("unsupported file type in archive: %s", f.Name)
+		}
+		inFile, err := f.Open()
+		if err != nil {
+			return err
+		}
+		size, err := writeArchiveFile(target, inFile, MaxArchiveSize-counter.size)
+		_ = inFile.Close()
+		if err != nil {
+			return err
+		}
+		if err = counter.add(size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 解压tar文件 (支持gzip压缩)
+func extractTar(archiveFile string, destDir string, gzipped bool) error {
+	file, err := os.Open(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if gzipped {
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+	tarReader := tar.NewReader(reader)
+	counter := archiveCounter{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		target, err := SafeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			size, err := writeArchiveFile(target, tarReader, MaxArchiveSize-counter.size)
+			if err != nil {
+				return err
+			}
+			if err = counter.add(size); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// ignore
+		default:
+			// 不解压符号链接等特殊文件
+			return errors.Errorf("unsupported file type in archive: %s", header.Name)
+		}
+	}
+	return nil
+}
+
+// ExtractArchive 安全地解压代码包到指定目录
+// 会拒绝目录穿越(zip-slip)、符号链接以及超出数量、大小限制的代码包
+func ExtractArchive(archiveFile string, destDir string) error {
+	name := strings.ToLower(archiveFile)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return extractZip(archiveFile, destDir)
+	case strings.HasSuffix(name, ".tar"):
+		return extractTar(archiveFile, destDir, false)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return extractTar(archiveFile, destDir, true)
+	}
+	return errors.Errorf("unsupported archive: %s", archiveFile)
+}
+
+// CopyDirectory 复制目录，符号链接等特殊文件会被忽略
+func CopyDirectory(srcDir string, destDir string) error {
+	counter := archiveCounter{}
+	return filepath.Walk(srcDir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, fpath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err = counter.add(info.Size()); err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// UnwrapSingleDirectory 如果目录下只有一个子目录(常见于把整个项目文件夹打包的情况)，返回这个子目录
+func UnwrapSingleDirectory(dir string) string {
+	for {
+		items, err := ioutil.ReadDir(dir)
+		if err != nil || len(items) != 1 || !items[0].IsDir() {
+			return dir
+		}
+		dir = filepath.Join(dir, items[0].Name())
+	}
+}
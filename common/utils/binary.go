@@ -12,7 +12,6 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
-	"strings"
 	"syscall"
 )
 
@@ -54,9 +53,12 @@ func GetCompiledBinaryFileAbsPath(typeName, moduleName, configDir string) (strin
 	return filepath.Abs(path.Join(path.Join(configDir, "bin"), targetName))
 }
 
-// ParseGeneratorScript 解析generator脚本
+// ParseGeneratorScript 解析generator脚本 (按shell的规则拆分参数)
 func ParseGeneratorScript(script string) (string, []string, error) {
-	vals := strings.Split(script, " ")
+	vals, err := ParseShellWords(script)
+	if err != nil {
+		return "", nil, err
+	}
 	if len(vals) <= 1 {
 		return "", nil, errors.Errorf("generator calling script error")
 	}
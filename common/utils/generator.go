@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GeneratorScriptItem generator脚本展开后的一条生成指令
+type GeneratorScriptItem struct {
+	Index     int      // 测试数据编号（从1开始）
+	Generator string   // Generator name
+	Args      []string // Generator arguments
+	Line      int      // 所在脚本的行号
+}
+
+// Command 转换为TestCase.Generator使用的调用脚本
+func (item GeneratorScriptItem) Command() string {
+	return QuoteShellWords(append([]string{item.Generator}, item.Args...))
+}
+
+var generatorRangeRegexp = regexp.MustCompile(`\{(-?\d+)\.\.(-?\d+)\}`)
+
+// 展开参数中的{a..b}区间，返回每个区间的取值
+func expandGeneratorRanges(args []string) ([][]string, error) {
+	result := [][]string{{}}
+	for _, arg := range args {
+		matched := generatorRangeRegexp.FindStringSubmatchIndex(arg)
+		if matched == nil {
+			for i := range result {
+				result[i] = append(result[i], arg)
+			}
+			continue
+		}
+		from, _ := strconv.Atoi(arg[matched[2]:matched[3]])
+		to, _ := strconv.Atoi(arg[matched[4]:matched[5]])
+		if from > to {
+			return nil, errors.Errorf("range {%d..%d} is empty", from, to)
+		}
+		// 同一个参数里只允许出现一个区间，剩下的部分原样保留
+		prefix, suffix := arg[:matched[0]], arg[matched[1]:]
+		next := make([][]string, 0, len(result)*(to-from+1))
+		for _, item := range result {
+			for v := from; v <= to; v++ {
+				expanded := make([]string, len(item), len(item)+1)
+				copy(expanded, item)
+				next = append(next, append(expanded, fmt.Sprintf("%s%d%s", prefix, v, suffix)))
+			}
+		}
+		result = next
+	}
+	return result, nil
+}
+
+// 去掉行内的注释：只有在引号外、位于行首或空白之后(没有被转义)的"#"才是注释的开始
+func stripGeneratorComment(line string) string {
+	var quote rune
+	escaped := false
+	for pos, ch := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (pos == 0 || line[pos-1] == ' ' || line[pos-1] == '\t'):
+			return strings.TrimSpace(line[:pos])
+		}
+	}
+	return line
+}
+
+// ParseGeneratorScriptFile 解析Polygon风格的generator脚本
+// 每行格式为: <generator> [args...] > <target>
+//   - target为"$"时使用下一个可用的编号，为数字时使用指定的编号
+//   - 参数中的{a..b}会被展开成多组测试数据（多个区间取笛卡尔积）
+//   - 参数中的${seed}会被替换为随机种子，种子从1开始，每生成一组数据自增1，可以用"@seed <N>"修改
+//   - 参数按shell的规则拆分，支持引号和反斜杠转义
+//   - "#"开始的内容为注释（引号中和紧跟在参数后的"#"不算）
+func ParseGeneratorScriptFile(reader io.Reader) ([]GeneratorScriptItem, error) {
+	items := make([]GeneratorScriptItem, 0)
+	used := map[int]int{}
+	lastIndex, seed, lineNo := 0, 1, 0
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		line = stripGeneratorComment(line)
+		if line == "" {
+			continue
+		}
+		// 指令
+		if strings.HasPrefix(line, "@") {
+			fields := strings.Fields(line)
+			if fields[0] != "@seed" || len(fields) != 2 {
+				return nil, errors.Errorf("line %d: unknown directive '%s'", lineNo, line)
+			}
+			v, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, errors.Errorf("line %d: seed must be an integer", lineNo)
+			}
+			seed = v
+			continue
+		}
+		pos := strings.LastIndex(line, ">")
+		if pos < 0 {
+			return nil, errors.Errorf("line %d: missing target, use '> $' or '> <index>'", lineNo)
+		}
+		fields, err := ParseShellWords(line[:pos])
+		if err != nil {
+			return nil, errors.Errorf("line %d: %s", lineNo, err.Error())
+		}
+		target := strings.TrimSpace(line[pos+1:])
+		if len(fields) == 0 {
+			return nil, errors.Errorf("line %d: missing generator name", lineNo)
+		}
+		expanded, err := expandGeneratorRanges(fields[1:])
+		if err != nil {
+			return nil, errors.Errorf("line %d: %s", lineNo, err.Error())
+		}
+		if target != "$" {
+			index, err := strconv.Atoi(target)
+			if err != nil || index <= 0 {
+				return nil, errors.Errorf("line %d: target must be '$' or a positive integer", lineNo)
+			}
+			if len(expanded) > 1 {
+				return nil, errors.Errorf("line %d: a line with ranges must use '$' as target", lineNo)
+			}
+			lastIndex = index - 1
+		}
+		for _, args := range expanded {
+			lastIndex++
+			if prev, ok := used[lastIndex]; ok {
+				return nil, errors.Errorf("line %d: test #%d has been defined at line %d", lineNo, lastIndex, prev)
+			}
+			used[lastIndex] = lineNo
+			for i := range args {
+				if strings.Contains(args[i], "${seed}") {
+					args[i] = strings.Replace(args[i], "${seed}", strconv.Itoa(seed), -1)
+				}
+			}
+			seed++
+			items = append(items, GeneratorScriptItem{
+				Index:     lastIndex,
+				Generator: fields[0],
+				Args:      args,
+				Line:      lineNo,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ParseShellWords 按shell的规则拆分命令字符串，支持单引号、双引号和反斜杠转义
+func ParseShellWords(command string) ([]string, error) {
+	args := make([]string, 0)
+	var buf strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, ch := range command {
+		if escaped {
+			// 双引号内只有部分字符可以被转义
+			if quote == '"' && !strings.ContainsRune("\\\"$`", ch) {
+				buf.WriteRune('\\')
+			}
+			buf.WriteRune(ch)
+			escaped = false
+			inWord = true
+			continue
+		}
+		switch {
+		case quote == '\'':
+			if ch == '\'' {
+				quote = 0
+			} else {
+				buf.WriteRune(ch)
+			}
+		case ch == '\\':
+			escaped = true
+		case quote == '"':
+			if ch == '"' {
+				quote = 0
+			} else {
+				buf.WriteRune(ch)
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			inWord = true
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			if inWord {
+				args = append(args, buf.String())
+				buf.Reset()
+				inWord = false
+			}
+		default:
+			buf.WriteRune(ch)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, errors.Errorf("unexpected end of command after backslash: %s", command)
+	}
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in command: %s", command)
+	}
+	if inWord {
+		args = append(args, buf.String())
+	}
+	return args, nil
+}
+
+// QuoteShellWords 把参数拼接为命令字符串，含有空白或特殊字符的参数用单引号括起来，可以被ParseShellWords还原
+func QuoteShellWords(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n\r'\"\\") {
+			quoted = append(quoted, arg)
+			continue
+		}
+		quoted = append(quoted, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
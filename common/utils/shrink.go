@@ -0,0 +1,44 @@
+package utils
+
+// ShrinkByDeltaDebugging 使用delta-debugging算法缩减units
+// 每次尝试删除其中的一段，如果test返回true(仍然能复现错误)则保留删除结果，直到无法继续缩减
+func ShrinkByDeltaDebugging(units []string, test func([]string) (bool, error)) ([]string, error) {
+	n := 2
+	for len(units) > 1 {
+		chunk := (len(units) + n - 1) / n
+		reduced := false
+		for start := 0; start < len(units); start += chunk {
+			end := start + chunk
+			if end > len(units) {
+				end = len(units)
+			}
+			candidate := make([]string, 0, len(units)-(end-start))
+			candidate = append(candidate, units[:start]...)
+			candidate = append(candidate, units[end:]...)
+			ok, err := test(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				units = candidate
+				reduced = true
+				break
+			}
+		}
+		if reduced {
+			if n > 2 {
+				n--
+			}
+			continue
+		}
+		// 已经是最细的粒度了
+		if n >= len(units) {
+			break
+		}
+		n *= 2
+		if n > len(units) {
+			n = len(units)
+		}
+	}
+	return units, nil
+}
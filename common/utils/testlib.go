@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"bufio"
+	"strings"
+)
+
+// TestlibBoundsHit testlib validator记录的变量边界命中情况
+type TestlibBoundsHit struct {
+	MinHit bool // 是否取到了最小值
+	MaxHit bool // 是否取到了最大值
+}
+
+// ParseTestlibOverviewLog 解析testlib validator的testOverviewLog
+// 每行的格式为 `"n": min-value-hit max-value-hit` 或 `feature "tree": hit`
+func ParseTestlibOverviewLog(content string) (map[string]TestlibBoundsHit, map[string]bool) {
+	bounds := map[string]TestlibBoundsHit{}
+	features := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		isFeature := strings.HasPrefix(line, "feature ")
+		if isFeature {
+			line = strings.TrimSpace(strings.TrimPrefix(line, "feature "))
+		}
+		if !strings.HasPrefix(line, "\"") {
+			continue
+		}
+		pos := strings.LastIndex(line, "\":")
+		if pos <= 0 {
+			continue
+		}
+		name := line[1:pos]
+		flags := strings.Fields(line[pos+2:])
+		if isFeature {
+			features[name] = Contains(flags, "hit")
+			continue
+		}
+		bounds[name] = TestlibBoundsHit{
+			MinHit: Contains(flags, "min-value-hit"),
+			MaxHit: Contains(flags, "max-value-hit"),
+		}
+	}
+	return bounds, features
+}
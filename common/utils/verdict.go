@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/pkg/errors"
+)
+
+// CheckAnswerVerdict 检查解答在各个测试数据上的评测结果是否符合期望的判定
+// 没有任何测试数据结果时(如编译错误)视为不符合
+func CheckAnswerVerdict(expected string, flags []int) (bool, error) {
+	// allowed: 允许出现的结果；required: 至少需要出现一次的结果
+	var allowed, required []int
+	switch expected {
+	case constants.AnswerVerdictMain, constants.AnswerVerdictAC, "":
+		allowed = []int{constants.JudgeFlagAC}
+	case constants.AnswerVerdictWA:
+		// PE也算作答案错误
+		allowed = []int{constants.JudgeFlagAC, constants.JudgeFlagWA, constants.JudgeFlagPE}
+		required = []int{constants.JudgeFlagWA, constants.JudgeFlagPE}
+	case constants.AnswerVerdictTLE:
+		allowed = []int{constants.JudgeFlagAC, constants.JudgeFlagTLE}
+		required = []int{constants.JudgeFlagTLE}
+	case constants.AnswerVerdictTLEOrAC:
+		allowed = []int{constants.JudgeFlagAC, constants.JudgeFlagTLE}
+	case constants.AnswerVerdictRE:
+		allowed = []int{constants.JudgeFlagAC, constants.JudgeFlagRE}
+		required = []int{constants.JudgeFlagRE}
+	default:
+		return false, errors.Errorf("unknown expected verdict '%s'", expected)
+	}
+	if len(flags) == 0 {
+		return false, nil
+	}
+	matched := len(required) == 0
+	for _, flag := range flags {
+		if !containsFlag(allowed, flag) {
+			return false, nil
+		}
+		if containsFlag(required, flag) {
+			matched = true
+		}
+	}
+	return matched, nil
+}
+
+func containsFlag(flags []int, flag int) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
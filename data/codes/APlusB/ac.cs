@@ -0,0 +1,15 @@
+using System;
+
+public class Program
+{
+    public static void Main()
+    {
+        string line;
+        while ((line = Console.ReadLine()) != null)
+        {
+            string[] items = line.Split(new char[] { ' ' }, StringSplitOptions.RemoveEmptyEntries);
+            if (items.Length < 2) continue;
+            Console.WriteLine(int.Parse(items[0]) + int.Parse(items[1]));
+        }
+    }
+}
@@ -0,0 +1,2 @@
+main :: IO ()
+main = interact $ unlines . map (show . sum . map read . words) . filter (not . null . words) . lines
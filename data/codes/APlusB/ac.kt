@@ -0,0 +1,8 @@
+fun main() {
+    while (true) {
+        val line = readLine() ?: break
+        val items = line.split(" ").filter { it.isNotEmpty() }
+        if (items.size < 2) continue
+        println(items[0].toInt() + items[1].toInt())
+    }
+}
@@ -0,0 +1,6 @@
+for line in io.lines() do
+  local a, b = line:match("(%-?%d+)%s+(%-?%d+)")
+  if a then
+    print(tonumber(a) + tonumber(b))
+  end
+end
@@ -0,0 +1,10 @@
+program APlusB;
+var
+  a, b: longint;
+begin
+  while not eof(input) do
+  begin
+    readln(a, b);
+    writeln(a + b);
+  end;
+end.
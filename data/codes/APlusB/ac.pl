@@ -0,0 +1,5 @@
+while (my $line = <STDIN>) {
+    my ($a, $b) = split(' ', $line);
+    next unless defined $b;
+    print $a + $b, "\n";
+}
@@ -0,0 +1,4 @@
+while read a b; do
+  [ -z "$b" ] && continue
+  echo $((a + b))
+done
@@ -0,0 +1,10 @@
+using System;
+
+public class Program
+{
+    public static void Main()
+    {
+        int[] items = new int[1];
+        Console.WriteLine(items[Console.ReadLine().Length]);
+    }
+}
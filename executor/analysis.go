@@ -59,7 +59,8 @@ func (session *JudgeSession) analysisExitStatus(rst *commonStructs.TestCaseResul
 	if judger {
 		if status.Signaled() {
 			sig := status.Signal()
-			if session.JudgeConfig.SpecialJudge.Mode != constants.SpecialJudgeModeInteractive {
+			mode := session.JudgeConfig.SpecialJudge.Mode
+			if mode != constants.SpecialJudgeModeInteractive && mode != constants.SpecialJudgeModeCommunication {
 				// 检查判题程序是否超时
 				if sig == syscall.SIGXCPU || sig == syscall.SIGALRM {
 					rst.JudgeResult = constants.JudgeFlagSpecialJudgeTimeout
@@ -69,7 +70,7 @@ func (session *JudgeSession) analysisExitStatus(rst *commonStructs.TestCaseResul
 					rst.ReInfo = fmt.Sprintf("special judger caused an error, unix singal: %d", sig)
 				}
 			} else {
-				// 交互特判时，如果选手程序让判题程序挂了，视作RE
+				// 交互特判(及通信题)时，如果选手程序让判题程序挂了，视作RE
 				rst.JudgeResult = constants.JudgeFlagRE
 				rst.ReInfo = fmt.Sprintf("special judger caused an error, unix singal: %d", sig)
 			}
@@ -154,6 +155,16 @@ func (session *JudgeSession) analysisExitStatus(rst *commonStructs.TestCaseResul
 				rst.JudgeResult = constants.JudgeFlagMLE
 			} else if rst.MemoryUsed > session.JudgeConfig.MemoryLimit {
 				rst.JudgeResult = constants.JudgeFlagMLE
+			} else if session.Compiler != nil && session.Compiler.IsExitCodeRuntimeError(status.ExitStatus()) {
+				// 带虚拟机的语言出现未捕获的异常时，进程会以非0的退出代码正常结束
+				rst.JudgeResult = constants.JudgeFlagRE
+				rst.ReInfo = fmt.Sprintf("program exited with code %d", status.ExitStatus())
+				if stderr, err := ioutil.ReadFile(path.Join(session.SessionDir, rst.ProgramError)); err == nil && len(stderr) > 0 {
+					if len(stderr) > 1024 {
+						stderr = stderr[:1024]
+					}
+					rst.ReInfo = fmt.Sprintf("%s\n%s", rst.ReInfo, string(stderr))
+				}
 			} else {
 				rst.JudgeResult = constants.JudgeFlagAC
 			}
@@ -170,7 +181,7 @@ func (session *JudgeSession) isDisastrousFault(judgeResult *commonStructs.JudgeR
 	}
 
 	// 如果是实时运行的语言
-	if session.Compiler.IsRealTime() {
+	if session.Compiler != nil && session.Compiler.IsRealTime() {
 		outfile, e := ioutil.ReadFile(path.Join(session.SessionDir, tcResult.ProgramError))
 		if e == nil {
 			if len(outfile) > 0 {
@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"encoding/json"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CodeFillError 代码填空的回答不符合要求 (判为编译错误)
+type CodeFillError struct {
+	message string
+}
+
+// Error get error string
+func (e CodeFillError) Error() string {
+	return e.message
+}
+
+// 按语言名称或family获取设置
+func getLanguageSetting(langName string, settings map[string]string) (string, bool) {
+	value, ok := settings[langName]
+	if !ok {
+		value, ok = settings[provider.GetLanguageFamily(langName)]
+	}
+	return value, ok
+}
+
+// 获取代码填空的语言，没有指定语言且只有一个代码模板时使用该模板的语言
+func (session *JudgeSession) getCodeFillLanguage() (*provider.LanguageDefinition, error) {
+	templates := session.JudgeConfig.Problem.CodeTemplates
+	keyword := session.CodeLangName
+	if keyword == "auto" || keyword == "" {
+		if len(templates) != 1 {
+			return nil, errors.Errorf("language is required for code-fill problems")
+		}
+		for name := range templates {
+			keyword = name
+		}
+	}
+	return provider.MatchLanguage(keyword, "")
+}
+
+// 检查回答是否包含禁止使用的关键字，标识符按整个单词匹配，其他的按子串匹配
+func checkForbiddenTokens(answer string, tokens []string) string {
+	identifier := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		if identifier.MatchString(token) {
+			if regexp.MustCompile(`\b` + token + `\b`).MatchString(answer) {
+				return token
+			}
+		} else if strings.Contains(answer, token) {
+			return token
+		}
+	}
+	return ""
+}
+
+// FillCodeTemplate 把各个空的回答插入到代码模板中
+// answers为handle到回答代码的映射，没有回答的空视为空白
+func FillCodeTemplate(
+	template string,
+	langName string,
+	demoCases map[string]commonStructs.JudgeDemoCase,
+	answers map[string]string,
+) (string, error) {
+	for handle := range answers {
+		if _, ok := demoCases[handle]; !ok {
+			return "", CodeFillError{message: "unknown blank: " + handle}
+		}
+	}
+	lines := strings.Split(template, "\n")
+	inserts := map[int][]string{}
+	handles := make([]string, 0, len(demoCases))
+	for handle := range demoCases {
+		handles = append(handles, handle)
+	}
+	// 同一位置的多个空按order排序，order相同时按handle排序
+	sort.Slice(handles, func(i, j int) bool {
+		a, b := demoCases[handles[i]], demoCases[handles[j]]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return handles[i] < handles[j]
+	})
+	for _, handle := range handles {
+		demo := demoCases[handle]
+		answer := answers[handle]
+		if demo.MaxLength > 0 && len(answer) > demo.MaxLength {
+			return "", CodeFillError{message: "answer of blank (" + handle + ") is too long"}
+		}
+		if token := checkForbiddenTokens(answer, demo.ForbiddenTokens); token != "" {
+			return "", CodeFillError{message: "answer of blank (" + handle + ") uses a forbidden token: " + token}
+		}
+		line := demo.Line
+		if value, ok := demo.Lines[langName]; ok {
+			line = value
+		} else if value, ok := demo.Lines[provider.GetLanguageFamily(langName)]; ok {
+			line = value
+		}
+		if line < 0 || line > len(lines) {
+			return "", errors.Errorf("blank (%s) line (%d) out of range", handle, line)
+		}
+		inserts[line] = append(inserts[line], strings.TrimRight(answer, "\n"))
+	}
+	// 按行号从大到小插入，保证行号对应原始的模板
+	positions := make([]int, 0, len(inserts))
+	for line := range inserts {
+		positions = append(positions, line)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+	for _, line := range positions {
+		filled := append([]string{}, lines[:line]...)
+		filled = append(filled, inserts[line]...)
+		lines = append(filled, lines[line:]...)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// 生成代码填空题的完整代码，并设置代码的语言，同时返回各个空的回答
+func (session *JudgeSession) fillCodeTemplate() (string, map[string]string, error) {
+	lang, err := session.getCodeFillLanguage()
+	if err != nil {
+		return "", nil, err
+	}
+	problem := session.JudgeConfig.Problem
+	templateFile, ok := getLanguageSetting(lang.Name, problem.CodeTemplates)
+	if !ok {
+		return "", nil, errors.Errorf("code template of language (%s) not exists", lang.Name)
+	}
+	template, err := ioutil.ReadFile(path.Join(session.ConfigDir, templateFile))
+	if err != nil {
+		return "", nil, errors.Errorf("read code template error: %s", err.Error())
+	}
+	submission := []byte(session.CodeContent)
+	if session.CodeContent == "" {
+		submission, err = ioutil.ReadFile(session.CodeFile)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	answers := map[string]string{}
+	if err = json.Unmarshal(submission, &answers); err != nil {
+		return "", nil, CodeFillError{message: "submission should be a JSON object of answers: " + err.Error()}
+	}
+	code, err := FillCodeTemplate(string(template), lang.Name, problem.DemoCases, answers)
+	if err != nil {
+		return "", nil, err
+	}
+	session.CodeLangName = lang.Name
+	return code, answers, nil
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// 通信题中manager的名称
+const communicationManager = "manager"
+
+// CommunicationEndpoint 进程持有的管道端点
+type CommunicationEndpoint struct {
+	Channel int  // Index of the channel
+	Write   bool // Write end (or read end)
+}
+
+// CommunicationTopology 通信题的进程和管道布局
+type CommunicationTopology struct {
+	Channels  []commonStructs.CommunicationChannel // Pipes
+	Instances []string                             // Instance names
+	// Pipe endpoints of each process in fd order.
+	// Instance: the first two are stdin and stdout, the others start from fd 3; manager: all start from fd 3
+	Endpoints map[string][]CommunicationEndpoint
+}
+
+// BuildCommunicationTopology 根据通信题设置生成进程和管道的布局
+func BuildCommunicationTopology(options commonStructs.CommunicationOptions) (*CommunicationTopology, error) {
+	count := options.Instances
+	if count <= 0 {
+		count = len(options.Roles)
+	}
+	if count <= 0 {
+		count = 2
+	}
+	if len(options.Roles) > 0 && len(options.Roles) != count {
+		return nil, errors.Errorf("communication roles count (%d) not equals to instances (%d)", len(options.Roles), count)
+	}
+	topology := CommunicationTopology{
+		Instances: make([]string, 0, count),
+		Endpoints: map[string][]CommunicationEndpoint{},
+	}
+	known := map[string]bool{communicationManager: true}
+	for i := 0; i < count; i++ {
+		name := strconv.Itoa(i)
+		if len(options.Roles) > 0 {
+			name = options.Roles[i]
+		}
+		if name == "" || known[name] {
+			return nil, errors.Errorf("invalid communication role name: %s", name)
+		}
+		known[name] = true
+		topology.Instances = append(topology.Instances, name)
+	}
+
+	topology.Channels = options.Channels
+	if len(topology.Channels) == 0 {
+		// 默认每个实例和manager之间各有一对管道
+		for _, name := range topology.Instances {
+			topology.Channels = append(
+				topology.Channels,
+				commonStructs.CommunicationChannel{From: communicationManager, To: name},
+				commonStructs.CommunicationChannel{From: name, To: communicationManager},
+			)
+		}
+	}
+	for _, channel := range topology.Channels {
+		if !known[channel.From] || !known[channel.To] || channel.From == channel.To {
+			return nil, errors.Errorf("invalid communication channel: %s -> %s", channel.From, channel.To)
+		}
+	}
+
+	for _, name := range topology.Instances {
+		// 第一个输入管道作为stdin，第一个输出管道作为stdout
+		stdin, stdout := -1, -1
+		for i, channel := range topology.Channels {
+			if stdin < 0 && channel.To == name {
+				stdin = i
+			}
+			if stdout < 0 && channel.From == name {
+				stdout = i
+			}
+		}
+		if stdin < 0 || stdout < 0 {
+			return nil, errors.Errorf("instance (%s) should have an input channel and an output channel", name)
+		}
+		endpoints := []CommunicationEndpoint{{Channel: stdin}, {Channel: stdout, Write: true}}
+		for i, channel := range topology.Channels {
+			if channel.To == name && i != stdin {
+				endpoints = append(endpoints, CommunicationEndpoint{Channel: i})
+			} else if channel.From == name && i != stdout {
+				endpoints = append(endpoints, CommunicationEndpoint{Channel: i, Write: true})
+			}
+		}
+		topology.Endpoints[name] = endpoints
+	}
+	for i, channel := range topology.Channels {
+		if channel.To == communicationManager {
+			topology.Endpoints[communicationManager] = append(topology.Endpoints[communicationManager], CommunicationEndpoint{Channel: i})
+		} else if channel.From == communicationManager {
+			topology.Endpoints[communicationManager] = append(topology.Endpoints[communicationManager], CommunicationEndpoint{Channel: i, Write: true})
+		}
+	}
+	return &topology, nil
+}
@@ -0,0 +1,244 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/cmd"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/forkexec"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// 通信题中一个进程的退出信息
+type communicationExit struct {
+	index int
+	err   error
+}
+
+// 通信题中已经启动的进程，清理之后启动的进程会被立即结束
+type communicationProcesses struct {
+	lock    sync.Mutex
+	pids    []int
+	cleaned bool
+}
+
+// 记录启动的进程，已经清理过时直接结束该进程
+func (cp *communicationProcesses) add(pid int) bool {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+	if cp.cleaned {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+		return false
+	}
+	cp.pids = append(cp.pids, pid)
+	return true
+}
+
+// 结束所有已经启动的进程
+func (cp *communicationProcesses) clean() {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+	cp.cleaned = true
+	for _, pid := range cp.pids {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// 关闭已经打开的文件 (不包括管道)
+func closeOpenedFiles(files []interface{}) {
+	for _, f := range files {
+		if fi, ok := f.(*os.File); ok {
+			fi.Close()
+		}
+	}
+}
+
+// 运行通信评测：一个manager进程和多个选手程序的实例，按照布局用管道连接
+// 返回manager和各个实例的进程信息，实例的顺序和topology.Instances相同
+func runCommunicationAsync(
+	ctx context.Context,
+	session *JudgeSession,
+	rst *commonStructs.TestCaseResult,
+	topology *CommunicationTopology,
+) (*ProcessInfo, []*ProcessInfo, error) {
+	var err error
+	pipes := make([]uintptr, 0, len(topology.Channels)*2)
+	for range topology.Channels {
+		var fd []uintptr
+		fd, err = forkexec.GetPipe()
+		if err != nil {
+			closeFiles(toFileList(pipes))
+			return nil, nil, errors.Errorf("create pipe error: %s", err.Error())
+		}
+		pipes = append(pipes, fd[0], fd[1])
+	}
+	endpointFiles := func(name string) []interface{} {
+		files := make([]interface{}, 0, len(topology.Endpoints[name]))
+		for _, ep := range topology.Endpoints[name] {
+			if ep.Write {
+				files = append(files, pipes[ep.Channel*2+1])
+			} else {
+				files = append(files, pipes[ep.Channel*2])
+			}
+		}
+		return files
+	}
+
+	// 第一个进程为manager，其余为各个实例
+	names := append([]string{communicationManager}, topology.Instances...)
+	pArgsList := make([]*PArgs, 0, len(names))
+	for _, name := range names {
+		var pArgs *PArgs
+		files := endpointFiles(name)
+		if name == communicationManager {
+			pArgs, err = getProcessOptions(session, rst, true, false, nil)
+			if err == nil {
+				pArgs.Attr.Files = append(pArgs.Attr.Files, files...)
+			}
+		} else {
+			// 每个实例的stderr单独保存
+			irst := *rst
+			irst.ProgramError = fmt.Sprintf("%s_%s.err", strings.TrimSuffix(rst.ProgramError, ".err"), name)
+			pArgs, err = getProcessOptions(session, &irst, false, true, []uintptr{files[0].(uintptr), files[1].(uintptr)})
+			if err == nil {
+				pArgs.Attr.Files = append(pArgs.Attr.Files, files[2:]...)
+				if len(session.JudgeConfig.SpecialJudge.Communication.Roles) > 0 {
+					pArgs.Args = append(append([]string{}, pArgs.Args...), name)
+				}
+			}
+		}
+		if err != nil {
+			for _, p := range pArgsList {
+				closeOpenedFiles(p.Attr.Files)
+			}
+			closeFiles(toFileList(pipes))
+			return nil, nil, err
+		}
+		pArgsList = append(pArgsList, pArgs)
+	}
+
+	procs := make([]ProcessInfo, len(pArgsList))
+	started := &communicationProcesses{}
+	exits := make(chan communicationExit, len(pArgsList))
+	for i, pArgs := range pArgsList {
+		go func(i int, pArgs *PArgs) {
+			// Start process
+			proc, err := cmd.StartProcess(pArgs.Name, pArgs.Args, pArgs.Attr)
+			if err != nil {
+				closeFiles(pArgs.Attr.Files)
+				exits <- communicationExit{index: i, err: err}
+				return
+			}
+			// Collect process info
+			procs[i].Process = proc
+			procs[i].Pid = proc.Pid
+			if started.add(proc.Pid) {
+				log.Printf("[Communication]Start %s process (%d)...\n", names[i], proc.Pid)
+			}
+			// Wait for exit.
+			pstate, err := proc.Wait()
+			if err != nil {
+				exits <- communicationExit{index: i, err: err}
+				return
+			}
+			log.Printf("Process (%d) exited.\n", proc.Pid)
+			procs[i].Status = pstate.Sys().(syscall.WaitStatus)
+			procs[i].Rusage = pstate.SysUsage().(*syscall.Rusage)
+			if procs[i].Rusage == nil {
+				exits <- communicationExit{index: i, err: errors.Errorf("get rusage failed")}
+				return
+			}
+			// 进程结束后关闭它持有的管道，另一端的进程才能读到EOF
+			closeFiles(pArgs.Attr.Files)
+			exits <- communicationExit{index: i}
+		}(i, pArgs)
+	}
+
+	var gErr error
+	for exitCounter := 0; exitCounter < len(pArgsList); {
+		select {
+		case exit := <-exits:
+			exitCounter++
+			if exit.err != nil {
+				gErr = errors.Errorf("%s process error: %s", names[exit.index], exit.err.Error())
+				goto doClean
+			}
+		case <-ctx.Done(): // 触发超时
+			log.Println("Child process timeout!")
+			gErr = errors.Errorf("Child process timeout!")
+			goto doClean
+		}
+	}
+	goto finish
+doClean:
+	started.clean()
+finish:
+	if gErr != nil {
+		return nil, nil, gErr
+	}
+	instances := make([]*ProcessInfo, 0, len(topology.Instances))
+	for i := 1; i < len(procs); i++ {
+		instances = append(instances, &procs[i])
+	}
+	return &procs[0], instances, nil
+}
+
+func toFileList(fds []uintptr) []interface{} {
+	files := make([]interface{}, 0, len(fds))
+	for _, fd := range fds {
+		files = append(files, fd)
+	}
+	return files
+}
+
+// 通信题评测：先分析manager的状态，manager正常结束后再依次分析各个实例
+func (session *JudgeSession) runCommunicationJudge(judgeResult *commonStructs.TestCaseResult) {
+	topology, err := BuildCommunicationTopology(session.JudgeConfig.SpecialJudge.Communication)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Interactor)
+	defer cancel()
+	manager, instances, err := runCommunicationAsync(ctx, session, judgeResult, topology)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	session.saveExitRusage(judgeResult, manager, true)
+	// 资源限制按实例分别计算，结果取各个实例的最大值
+	instanceResults := make([]commonStructs.TestCaseResult, len(instances))
+	for i, pinfo := range instances {
+		session.saveExitRusage(&instanceResults[i], pinfo, false)
+		judgeResult.TimeUsed = Max32(judgeResult.TimeUsed, instanceResults[i].TimeUsed)
+		judgeResult.MemoryUsed = Max32(judgeResult.MemoryUsed, instanceResults[i].MemoryUsed)
+	}
+	// 分析manager的状态
+	session.analysisExitStatus(judgeResult, manager, true)
+	if judgeResult.JudgeResult != 0 {
+		return
+	}
+	// 如果manager正常退出，则结果为第一个出错的实例的结果
+	for i, pinfo := range instances {
+		ir := &instanceResults[i]
+		session.analysisExitStatus(ir, pinfo, false)
+		if ir.JudgeResult != constants.JudgeFlagAC {
+			judgeResult.JudgeResult = ir.JudgeResult
+			judgeResult.ReSignum = ir.ReSignum
+			judgeResult.ReInfo = fmt.Sprintf("instance (%s): %s", topology.Instances[i], ir.ReInfo)
+			return
+		}
+	}
+}
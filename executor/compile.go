@@ -10,43 +10,24 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
-	"strings"
 )
 
-// 匹配编程语言
-func matchCodeLanguage(keyword string, fileName string) (provider.CodeCompileProviderInterface, error) {
-_match:
-	switch keyword {
-	case "c", "gcc", "gnu-c":
-		return provider.NewGnucCompileProvider(), nil
-	case "cpp", "gcc-cpp", "gcpp", "g++":
-		return provider.NewGnucppCompileProvider(), nil
-	case "java":
-		return provider.NewJavaCompileProvider(), nil
-	case "py2", "python2":
-		return provider.NewPy2CompileProvider(), nil
-	case "py", "py3", "python3":
-		return provider.NewPy3CompileProvider(), nil
-	case "php":
-		return provider.NewPHPCompileProvider(), nil
-	case "go", "golang":
-		return provider.NewGolangCompileProvider(), nil
-	case "node", "nodejs":
-		return provider.NewNodeJSCompileProvider(), nil
-	case "rb", "ruby":
-		return provider.NewRubyCompileProvider(), nil
-	case "rs", "rust":
-		return provider.NewRustCompileProvider(), nil
-	case "auto", "":
-		keyword = strings.Replace(path.Ext(fileName), ".", "", -1)
-		goto _match
-	}
-	return nil, errors.Errorf("unsupported language")
+// MatchCodeLanguage 匹配编程语言，keyword为auto时按文件扩展名识别 (按代码内容识别见provider.MatchLanguageWithCode)
+func MatchCodeLanguage(keyword string, fileName string) (provider.CodeCompileProviderInterface, error) {
+	lang, err := provider.MatchLanguage(keyword, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return provider.NewGenericCompileProvider(lang), nil
 }
 
 // GetCompiler get a complier provider from session.CodeLangName
 // 如果不设置codeStr，默认会读取配置文件里的code_file字段并打开对应文件
+// code_file为代码包或目录时，按多文件提交处理；题目设置了当前语言的grader时，和grader一起编译
 func (session *JudgeSession) GetCompiler(codeStr string) (provider.CodeCompileProviderInterface, error) {
+	if codeStr == "" && session.IsProjectSubmission() {
+		return session.getProjectCompiler()
+	}
 	if codeStr == "" {
 		codeFileBytes, err := ioutil.ReadFile(session.CodeFile)
 		if err != nil {
@@ -55,10 +36,27 @@ func (session *JudgeSession) GetCompiler(codeStr string) (provider.CodeCompilePr
 		codeStr = string(codeFileBytes)
 	}
 
-	compiler, err := matchCodeLanguage(session.CodeLangName, session.CodeFile)
+	lang, guesses, err := provider.MatchLanguageWithCode(session.CodeLangName, session.CodeFile, codeStr)
+	if len(guesses) > 0 {
+		// 按代码内容识别语言时，在日志中记录识别的结果
+		for i, guess := range guesses {
+			if i >= 3 {
+				break
+			}
+			session.Logger.Infof("Language guess #%d: %s", i+1, guess.String())
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	if guesses != nil {
+		session.Logger.Infof("Detected language: %s", lang.Name)
+	}
+	// 函数实现题，和grader一起编译
+	if grader, ok := session.getGraderOptions(lang.Name); ok {
+		return session.getGraderCompiler(lang, grader, codeStr)
+	}
+	compiler := provider.NewGenericCompileProvider(lang)
 	err = compiler.Init(codeStr, session.SessionDir)
 	if err != nil {
 		return nil, err
@@ -68,8 +66,26 @@ func (session *JudgeSession) GetCompiler(codeStr string) (provider.CodeCompilePr
 
 // 编译目标程序
 func (session *JudgeSession) compileTargetProgram(judgeResult *commonStructs.JudgeResult) error {
+	code := session.CodeContent
+	var answers map[string]string
+	// 代码填空题，把回答插入到代码模板中
+	if session.JudgeConfig.Problem.ProblemType == constants.ProblemTypeCodeFill {
+		var err error
+		code, answers, err = session.fillCodeTemplate()
+		if err != nil {
+			if _, ok := err.(CodeFillError); ok {
+				judgeResult.JudgeResult = constants.JudgeFlagCE
+				judgeResult.CeInfo = err.Error()
+			} else {
+				judgeResult.JudgeResult = constants.JudgeFlagSE
+				judgeResult.SeInfo = err.Error()
+			}
+			session.Logger.Error(err.Error())
+			return err
+		}
+	}
 	// 获取对应的编译器提供程序
-	compiler, err := session.GetCompiler("")
+	compiler, err := session.GetCompiler(code)
 	if err != nil {
 		judgeResult.JudgeResult = constants.JudgeFlagSE
 		judgeResult.SeInfo = err.Error()
@@ -77,9 +93,17 @@ func (session *JudgeSession) compileTargetProgram(judgeResult *commonStructs.Jud
 		return err
 	}
 
+	// 编译前的静态检查
+	if err = session.lintSubmission(judgeResult, compiler, code, answers); err != nil {
+		return err
+	}
+
 	// 编译程序
+	compiler.SetCompileTimeout(session.compileTimeout())
 	session.Logger.Infof("Do complie or syntax checkup, Language: %s", session.CodeLangName)
 	success, ceinfo := compiler.Compile()
+	// 编译成功时也保留警告信息
+	judgeResult.Diagnostics = compiler.Diagnostics()
 	if !success {
 		judgeResult.JudgeResult = constants.JudgeFlagCE
 		judgeResult.CeInfo = ceinfo
@@ -95,27 +119,25 @@ func (session *JudgeSession) compileTargetProgram(judgeResult *commonStructs.Jud
 }
 
 // 编译裁判程序
-// 如果有已经编译好的裁判程序，则直接返回这个程序
-// 打包的时候不会打包二进制文件，重新编译一次
+// 有源码时总是重新编译 (源码没有变化时会直接使用编译产物缓存)，避免使用过期的程序
+// 只有在源码不存在时，才使用bin目录下已经编译好的裁判程序
 func (session *JudgeSession) compileJudgerProgram(judgeResult *commonStructs.JudgeResult) error {
-	// 检查是否存在已经编译好的裁判程序
-	cType := "checker"
-	if session.JudgeConfig.SpecialJudge.Mode == 2 {
-		cType = "interactor"
-	}
-	cPath, err := utils.GetCompiledBinaryFileAbsPath(cType, session.JudgeConfig.SpecialJudge.Name, session.ConfigDir)
-	// 如果有已经编译好的裁判程序，则直接返回这个程序
-	if err == nil {
-		if s, err := os.Stat(cPath); err == nil && !s.IsDir() {
-			session.JudgeConfig.SpecialJudge.Checker = cPath
-			return nil
-		}
-	}
-
-	// 如果没有，则检查checker是否被设置
+	// 检查checker是否被设置
 	jCodeOrExec := path.Join(session.ConfigDir, session.JudgeConfig.SpecialJudge.Checker)
 	s, err := os.Stat(jCodeOrExec)
 	if os.IsNotExist(err) || s.IsDir() {
+		// 如果有已经编译好的裁判程序，则直接返回这个程序
+		cType := "checker"
+		if session.JudgeConfig.SpecialJudge.Mode >= constants.SpecialJudgeModeInteractive {
+			cType = "interactor"
+		}
+		cPath, err := utils.GetCompiledBinaryFileAbsPath(cType, session.JudgeConfig.SpecialJudge.Name, session.ConfigDir)
+		if err == nil {
+			if s, err := os.Stat(cPath); err == nil && !s.IsDir() {
+				session.JudgeConfig.SpecialJudge.Checker = cPath
+				return nil
+			}
+		}
 		judgeResult.JudgeResult = constants.JudgeFlagSE
 		judgeResult.SeInfo = fmt.Sprintf("checker file not exists")
 		session.Logger.Error("checker file not exists")
@@ -150,6 +172,7 @@ func (session *JudgeSession) compileJudgerProgram(judgeResult *commonStructs.Jud
 		config.ConfigDir,
 		session.LibraryDir,
 		config.SpecialJudge.CheckerLang,
+		session.compileTimeout(),
 	)
 	if err != nil {
 		judgeResult.JudgeResult = constants.JudgeFlagSE
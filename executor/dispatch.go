@@ -4,6 +4,7 @@ package executor
 
 import (
 	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
 	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
 	"github.com/pkg/errors"
 	"os"
@@ -13,9 +14,31 @@ import (
 
 // JudgeOnce 基于JudgeOptions进行评测调度
 func (session *JudgeSession) JudgeOnce(judgeResult *commonStructs.TestCaseResult) {
+	// 单元测试模式只运行测试程序，结果由测试报告决定
+	if session.JudgeConfig.Problem.ProblemType == constants.ProblemTypeUnitTest {
+		session.runUnitTestHarness(judgeResult)
+		return
+	}
+	// SQL题在每组测试数据的数据库上执行查询
+	if session.JudgeConfig.Problem.ProblemType == constants.ProblemTypeSQL {
+		session.runSQLJudge(judgeResult)
+		return
+	}
+	// 提交答案题直接检查提交的输出文件
+	if session.JudgeConfig.Problem.ProblemType == constants.ProblemTypeOutputOnly {
+		session.judgeSubmittedOutput(judgeResult)
+		return
+	}
 	switch session.JudgeConfig.SpecialJudge.Mode {
 	case constants.SpecialJudgeModeDisabled:
-		pinfo, err := session.runNormalJudge(judgeResult)
+		var pinfo *ProcessInfo
+		var comparator *StreamComparator
+		var err error
+		if session.useStreamCompare() {
+			pinfo, comparator, err = session.runStreamJudge(judgeResult)
+		} else {
+			pinfo, err = session.runNormalJudge(judgeResult)
+		}
 		if err != nil {
 			judgeResult.JudgeResult = constants.JudgeFlagSE
 			judgeResult.SeInfo = err.Error()
@@ -23,6 +46,11 @@ func (session *JudgeSession) JudgeOnce(judgeResult *commonStructs.TestCaseResult
 			return
 		}
 		session.saveExitRusage(judgeResult, pinfo, false)
+		// 运行时已经得到确定的WA或OLE，程序是被提前结束的，不再分析退出状态
+		if comparator != nil && comparator.Verdict != 0 {
+			session.applyStreamVerdict(judgeResult, comparator)
+			return
+		}
 		// 分析目标程序的状态
 		session.analysisExitStatus(judgeResult, pinfo, false)
 		// 只有AC的时候才进行文本比较！
@@ -38,6 +66,9 @@ func (session *JudgeSession) JudgeOnce(judgeResult *commonStructs.TestCaseResult
 			}
 		}
 
+	case constants.SpecialJudgeModeCommunication:
+		session.runCommunicationJudge(judgeResult)
+
 	case constants.SpecialJudgeModeChecker, constants.SpecialJudgeModeInteractive:
 		tinfo, jinfo, err := session.runSpecialJudge(judgeResult)
 		if err != nil {
@@ -116,6 +147,60 @@ func (session *JudgeSession) runOneCase(config *commonStructs.JudgeConfiguration
 	return &tcResult
 }
 
+// PrepareJudge 编译目标程序和裁判程序，并更新资源限制
+func (session *JudgeSession) PrepareJudge(judgeResult *commonStructs.JudgeResult) error {
+	var err error
+	problemType := session.JudgeConfig.Problem.ProblemType
+	if problemType == constants.ProblemTypeOutputOnly || problemType == constants.ProblemTypeSQL {
+		// 提交答案题不需要编译，只解出提交的输出文件；SQL题只需要保存查询语句
+		if problemType == constants.ProblemTypeOutputOnly {
+			err = session.unpackSubmittedOutputs()
+		} else {
+			err = session.prepareSQLJudge()
+		}
+		if err != nil {
+			if _, ok := err.(SQLSubmissionError); ok {
+				judgeResult.JudgeResult = constants.JudgeFlagCE
+				judgeResult.CeInfo = err.Error()
+			} else {
+				judgeResult.JudgeResult = constants.JudgeFlagSE
+				judgeResult.SeInfo = err.Error()
+			}
+			session.Logger.Error(err.Error())
+			return err
+		}
+	} else {
+		// compile code
+		err = session.compileTargetProgram(judgeResult)
+		if err != nil {
+			return err
+		}
+	}
+
+	if session.JudgeConfig.SpecialJudge.Mode > 0 {
+		// 如果需要特殊评测，则编译相关代码
+		err := session.compileJudgerProgram(judgeResult)
+		if err != nil {
+			judgeResult.JudgeResult = constants.JudgeFlagSE
+			judgeResult.SeInfo = err.Error()
+			return err
+		}
+	}
+
+	// 资源限制信息更新
+	if session.Compiler != nil {
+		updateLimitation(session)
+	}
+	// 预热模式下启动预热进程 (需要在资源限制确定之后)
+	session.startZygote(judgeResult)
+	return nil
+}
+
+// JudgeTestCase 对单组测试数据进行评测 (需要先调用PrepareJudge)
+func (session *JudgeSession) JudgeTestCase(tc commonStructs.TestCase) *commonStructs.TestCaseResult {
+	return session.runOneCase(&session.JudgeConfig, tc, tc.Handle)
+}
+
 // RunJudge 执行评测
 func (session *JudgeSession) RunJudge() commonStructs.JudgeResult {
 	session.Logger.Info("Start Judgement")
@@ -124,37 +209,48 @@ func (session *JudgeSession) RunJudge() commonStructs.JudgeResult {
 	judgeResult := commonStructs.JudgeResult{}
 	judgeResult.SessionID = session.SessionID
 
-	// compile code
-	err := session.compileTargetProgram(&judgeResult)
+	// 评测总预算耗尽时，正在运行的程序都会被结束
+	stopBudget := session.startBudget()
+	defer stopBudget()
+
+	err := session.PrepareJudge(&judgeResult)
+	defer session.StopZygote()
 	if err != nil {
+		if session.isBudgetExceeded() {
+			session.abortJudge(&judgeResult)
+		}
 		judgeResult.JudgeLogs = session.Logger.GetLogs()
 		return judgeResult
 	}
 
-	if session.JudgeConfig.SpecialJudge.Mode > 0 {
-		// 如果需要特殊评测，则编译相关代码
-		err := session.compileJudgerProgram(&judgeResult)
-		if err != nil {
-			judgeResult.JudgeResult = constants.JudgeFlagSE
-			judgeResult.SeInfo = err.Error()
-			judgeResult.JudgeLogs = session.Logger.GetLogs()
-			return judgeResult
+	session.Logger.Info("Ready for judgement")
+	if session.JudgeConfig.Problem.ProblemType == constants.ProblemTypeUnitTest {
+		session.runUnitTestJudge(&judgeResult)
+		if session.isBudgetExceeded() {
+			session.abortJudge(&judgeResult)
 		}
+		judgeResult.JudgeLogs = session.Logger.GetLogs()
+		return judgeResult
 	}
-
-	// 资源限制信息更新
-	updateLimitation(session)
-
-	session.Logger.Info("Ready for judgement")
 	// Init exit code
 	exitCodes := make([]int, 0, 1)
+	aborted := false
 	for i := 0; i < len(session.JudgeConfig.TestCases); i++ {
+		if session.isBudgetExceeded() {
+			aborted = true
+			break
+		}
 		if session.JudgeConfig.TestCases[i].Handle == "" {
 			session.JudgeConfig.TestCases[i].Handle = strconv.Itoa(i)
 		}
 		id := session.JudgeConfig.TestCases[i].Handle
 
 		tcResult := session.runOneCase(&session.JudgeConfig, session.JudgeConfig.TestCases[i], id)
+		// 被总预算中止的测试数据没有有效的结果，不计入
+		if session.isBudgetExceeded() && tcResult.JudgeResult == constants.JudgeFlagSE {
+			aborted = true
+			break
+		}
 
 		flagName, ok := constants.FlagMeansMap[tcResult.JudgeResult]
 		if !ok {
@@ -181,11 +277,18 @@ func (session *JudgeSession) RunJudge() commonStructs.JudgeResult {
 			keep = true
 		} else if !session.JudgeConfig.StrictMode && tcResult.JudgeResult == constants.JudgeFlagWA {
 			keep = true
+		} else if session.RunAllCases {
+			keep = true
 		}
 		if !keep {
 			break
 		}
 	}
+	if aborted {
+		session.abortJudge(&judgeResult)
+		judgeResult.JudgeLogs = session.Logger.GetLogs()
+		return judgeResult
+	}
 	// 计算最终结果
 	session.generateFinallyResult(&judgeResult, exitCodes)
 
@@ -208,12 +311,12 @@ func (session *JudgeSession) RunJudge() commonStructs.JudgeResult {
 // 从资源限制的参数列表里按语言获取相关信息，并作为当前的资源限制参数。
 func updateLimitation(session *JudgeSession) {
 	langName := session.Compiler.GetName()
-	memoryLimitExtend := 0
-	jitMem, ok := constants.MemorySizeForJIT[langName]
-	if ok {
-		memoryLimitExtend = jitMem
-	}
+	memoryLimitExtend := provider.GetJITMemory(langName)
+	// 优先使用具体语言版本的设置，其次使用语言family的设置
 	limitation, ok := session.JudgeConfig.Limitation[langName]
+	if !ok {
+		limitation, ok = session.JudgeConfig.Limitation[provider.GetLanguageFamily(langName)]
+	}
 	if ok {
 		session.JudgeConfig.TimeLimit = limitation.TimeLimit
 		session.JudgeConfig.MemoryLimit = limitation.MemoryLimit + memoryLimitExtend
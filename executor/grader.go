@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// 获取语言对应的grader设置，优先使用具体语言版本的设置，其次使用语言family的设置
+func (session *JudgeSession) getGraderOptions(langName string) (commonStructs.GraderOptions, bool) {
+	grader, ok := session.JudgeConfig.Graders[langName]
+	if !ok {
+		grader, ok = session.JudgeConfig.Graders[provider.GetLanguageFamily(langName)]
+	}
+	return grader, ok && len(grader.Files) > 0
+}
+
+// 获取函数实现题的编译器提供程序
+// grader文件和提交的代码会被放到会话目录下的grader目录中，作为多文件项目编译
+func (session *JudgeSession) getGraderCompiler(
+	lang *provider.LanguageDefinition,
+	grader commonStructs.GraderOptions,
+	code string,
+) (provider.CodeCompileProviderInterface, error) {
+	buildDir := path.Join(session.SessionDir, "grader")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return nil, err
+	}
+	for _, file := range grader.Files {
+		content, err := ioutil.ReadFile(path.Join(session.ConfigDir, file))
+		if err != nil {
+			return nil, errors.Errorf("read grader file (%s) error: %s", file, err.Error())
+		}
+		if err = ioutil.WriteFile(path.Join(buildDir, path.Base(file)), content, 0644); err != nil {
+			return nil, err
+		}
+	}
+	submissionName := grader.SubmissionName
+	if submissionName == "" {
+		submissionName = lang.SourceFileName(code, "solution")
+	}
+	err := ioutil.WriteFile(path.Join(buildDir, path.Base(submissionName)), []byte(code), 0644)
+	if err != nil {
+		return nil, err
+	}
+	entry := grader.Entry
+	if entry == "" {
+		entry = grader.Files[0]
+	}
+	compiler, err := provider.NewProjectCompileProvider(lang.Name, buildDir, commonStructs.ProjectOptions{
+		Entry: path.Base(entry),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = compiler.Init("", session.SessionDir); err != nil {
+		return nil, err
+	}
+	session.Logger.Infof("Function implementation problem: %s", compiler.String())
+	return compiler, nil
+}
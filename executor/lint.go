@@ -0,0 +1,303 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintViolation 违反静态检查规则的位置和原因
+type LintViolation struct {
+	File string // Source file of a multi-file submission, or blank of a code-fill submission (optional)
+	Line int    // Line number (1-based, 0 means the whole file)
+	Rule string // Violated rule
+	Text string // Offending line
+}
+
+func (v *LintViolation) Error() string {
+	message := fmt.Sprintf("line %d: %s: %s", v.Line, v.Rule, v.Text)
+	if v.Line == 0 {
+		message = v.Rule
+	}
+	if v.File != "" {
+		return v.File + ": " + message
+	}
+	return message
+}
+
+// 头文件、import语句 (按family)，第一个分组为模块名；分组中包含引号时取出其中全部的字符串，否则按逗号分隔
+var lintImportPatterns = map[string][]*regexp.Regexp{
+	"gcc":    {regexp.MustCompile(`(?m)^[ \t]*#[ \t]*include[ \t]*[<"]([^>"\n]+)[>"]`)},
+	"g++":    {regexp.MustCompile(`(?m)^[ \t]*#[ \t]*include[ \t]*[<"]([^>"\n]+)[>"]`)},
+	"java":   {regexp.MustCompile(`(?m)^[ \t]*import[ \t]+(?:static[ \t]+)?([\w.]+(?:\.\*)?)[ \t]*;`)},
+	"kotlin": {regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([\w.]+(?:\.\*)?)`)},
+	"csharp": {regexp.MustCompile(`(?m)^[ \t]*using[ \t]+(?:static[ \t]+)?([\w.]+)[ \t]*;`)},
+	"golang": {
+		regexp.MustCompile(`(?m)^[ \t]*import[ \t]+(?:[\w.]+[ \t]+)?("[^"\n]+")`),
+		regexp.MustCompile(`\bimport[ \t]*\(([^)]*)\)`),
+	},
+	"rust": {
+		regexp.MustCompile(`(?m)^[ \t]*(?:pub[ \t]+)?use[ \t]+([\w:]+)`),
+		regexp.MustCompile(`(?m)^[ \t]*extern[ \t]+crate[ \t]+(\w+)`),
+	},
+	"nodejs": {
+		regexp.MustCompile(`\brequire[ \t]*\([ \t]*['"]([^'"\n]+)['"]`),
+		regexp.MustCompile(`(?m)^[ \t]*import\b[^\n]*?\bfrom[ \t]*['"]([^'"\n]+)['"]`),
+	},
+	"python3": {
+		regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([^\n#;]+)`),
+		regexp.MustCompile(`(?m)^[ \t]*from[ \t]+([\w.]+)[ \t]+import\b`),
+	},
+	"python2": {
+		regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([^\n#;]+)`),
+		regexp.MustCompile(`(?m)^[ \t]*from[ \t]+([\w.]+)[ \t]+import\b`),
+	},
+}
+
+var lintQuotedPattern = regexp.MustCompile(`"([^"\n]+)"`)
+
+// 偏移量所在的行号和该行的内容
+func lintLineAt(code string, offset int) (int, string) {
+	start := strings.LastIndexByte(code[:offset], '\n') + 1
+	end := strings.IndexByte(code[offset:], '\n')
+	if end < 0 {
+		end = len(code)
+	} else {
+		end += offset
+	}
+	return strings.Count(code[:offset], "\n") + 1, strings.TrimSpace(code[start:end])
+}
+
+// 头文件、模块名是否匹配规则：完全相同、以规则加分隔符开头，或者规则以*结尾时前缀相同
+func lintImportMatches(name string, rule string) bool {
+	if strings.HasSuffix(rule, "*") {
+		return strings.HasPrefix(name, strings.TrimSuffix(rule, "*"))
+	}
+	if name == rule {
+		return true
+	}
+	for _, sep := range []string{".", "/", "::"} {
+		if strings.HasPrefix(name, rule+sep) {
+			return true
+		}
+	}
+	return false
+}
+
+func lintImportAllowed(name string, options commonStructs.LintOptions) (bool, string) {
+	for _, rule := range options.ForbiddenImports {
+		if lintImportMatches(name, rule) {
+			return false, fmt.Sprintf("import `%s` is forbidden", name)
+		}
+	}
+	if len(options.AllowedImports) == 0 {
+		return true, ""
+	}
+	for _, rule := range options.AllowedImports {
+		if lintImportMatches(name, rule) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("import `%s` is not allowed", name)
+}
+
+// 检查头文件和import语句
+// 在去掉注释和字符串的代码(masked)中查找语句，避免匹配到字符串中的内容；
+// 模块名可能写在字符串中(如#include "x.h"、go的import)，按相同的偏移量从只去掉注释的代码(withStrings)中读取
+func lintImports(source string, masked string, withStrings string, family string, options commonStructs.LintOptions) *LintViolation {
+	if len(options.AllowedImports) == 0 && len(options.ForbiddenImports) == 0 {
+		return nil
+	}
+	for _, pattern := range lintImportPatterns[family] {
+		for _, matched := range pattern.FindAllStringSubmatchIndex(masked, -1) {
+			group := withStrings[matched[2]:matched[3]]
+			// 模块名和所在的偏移量 (go的import块中每个模块各占一行)
+			names, offsets := make([]string, 0), make([]int, 0)
+			if strings.Contains(group, `"`) {
+				for _, item := range lintQuotedPattern.FindAllStringSubmatchIndex(group, -1) {
+					names = append(names, group[item[2]:item[3]])
+					offsets = append(offsets, matched[2]+item[2])
+				}
+			} else {
+				for _, item := range strings.Split(group, ",") {
+					if fields := strings.Fields(item); len(fields) > 0 {
+						names = append(names, fields[0])
+						offsets = append(offsets, matched[2])
+					}
+				}
+			}
+			for k, name := range names {
+				if ok, rule := lintImportAllowed(name, options); !ok {
+					line, text := lintLineAt(source, offsets[k])
+					return &LintViolation{Line: line, Rule: rule, Text: text}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// 关键字的正则，两端为单词字符时按整个单词匹配
+func lintKeywordPattern(keyword string) *regexp.Regexp {
+	isWord := func(c byte) bool {
+		return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	pattern := regexp.QuoteMeta(keyword)
+	if isWord(keyword[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWord(keyword[len(keyword)-1]) {
+		pattern += `\b`
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// LintCode 按规则检查代码，注释和字符串中的内容不会被检查
+// 返回第一处违反规则的位置；规则中的正则有误时返回error
+func LintCode(code string, langName string, options commonStructs.LintOptions) (*LintViolation, error) {
+	family := provider.GetLanguageFamily(langName)
+	withStrings, masked := code, code
+	if syntax, ok := provider.GetSourceSyntax(langName); ok {
+		withStrings = provider.MaskSource(code, syntax, false)
+		masked = provider.MaskSource(code, syntax, true)
+	}
+	if violation := lintImports(code, masked, withStrings, family, options); violation != nil {
+		return violation, nil
+	}
+	for _, keyword := range options.Keywords {
+		if keyword == "" {
+			continue
+		}
+		if loc := lintKeywordPattern(keyword).FindStringIndex(masked); loc != nil {
+			line, text := lintLineAt(code, loc[0])
+			return &LintViolation{Line: line, Rule: fmt.Sprintf("keyword `%s` is forbidden", keyword), Text: text}, nil
+		}
+	}
+	for _, item := range options.Patterns {
+		pattern, err := regexp.Compile(item)
+		if err != nil {
+			return nil, errors.Errorf("invalid lint pattern (%s): %s", item, err.Error())
+		}
+		if loc := pattern.FindStringIndex(masked); loc != nil {
+			line, text := lintLineAt(code, loc[0])
+			return &LintViolation{Line: line, Rule: fmt.Sprintf("pattern `%s` is forbidden", item), Text: text}, nil
+		}
+	}
+	return nil, nil
+}
+
+// 获取语言对应的静态检查规则，依次按语言名称、family和"*"查找
+func (session *JudgeSession) getLintOptions(langName string) (commonStructs.LintOptions, bool) {
+	for _, key := range []string{langName, provider.GetLanguageFamily(langName), "*"} {
+		if options, ok := session.JudgeConfig.Lint[key]; ok {
+			return options, true
+		}
+	}
+	return commonStructs.LintOptions{}, false
+}
+
+// LintProject 检查多文件提交中的全部文件，每个文件按扩展名识别的语言检查，无法识别时按提交的语言检查
+// 文件无法读取时同样视为违反规则，不会跳过
+func LintProject(projectDir string, langName string, options commonStructs.LintOptions) (*LintViolation, error) {
+	files, err := provider.ListProjectFiles(projectDir)
+	if err != nil {
+		return &LintViolation{Rule: "lint rules cannot be applied: " + err.Error()}, nil
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(path.Join(projectDir, file))
+		if err != nil {
+			return &LintViolation{File: file, Rule: "lint rules cannot be applied: " + err.Error()}, nil
+		}
+		fileLang := langName
+		if lang, err := provider.MatchLanguage("auto", file); err == nil {
+			fileLang = lang.Name
+		}
+		violation, err := LintCode(string(content), fileLang, options)
+		if err != nil {
+			return nil, err
+		}
+		if violation != nil {
+			violation.File = file
+			return violation, nil
+		}
+	}
+	return nil, nil
+}
+
+// LintCodeFillAnswers 检查代码填空题的各个回答 (按handle排序)，代码模板中的内容不检查
+func LintCodeFillAnswers(answers map[string]string, langName string, options commonStructs.LintOptions) (*LintViolation, error) {
+	handles := make([]string, 0, len(answers))
+	for handle := range answers {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+	for _, handle := range handles {
+		violation, err := LintCode(answers[handle], langName, options)
+		if err != nil {
+			return nil, err
+		}
+		if violation != nil {
+			violation.File = "blank (" + handle + ")"
+			return violation, nil
+		}
+	}
+	return nil, nil
+}
+
+// 编译前的静态检查，违反规则时判为Forbidden Code
+// 代码填空题只检查回答(answers)，多文件提交检查项目中的全部文件
+func (session *JudgeSession) lintSubmission(
+	judgeResult *commonStructs.JudgeResult,
+	compiler provider.CodeCompileProviderInterface,
+	code string,
+	answers map[string]string,
+) error {
+	langName := compiler.GetName()
+	options, ok := session.getLintOptions(langName)
+	if !ok {
+		return nil
+	}
+	session.Logger.Infof("Run lint checkup, Language: %s", langName)
+	var violation *LintViolation
+	var err error
+	if answers != nil {
+		violation, err = LintCodeFillAnswers(answers, langName, options)
+	} else if session.IsProjectSubmission() {
+		if project, ok := compiler.(*provider.ProjectCompileProvider); ok {
+			violation, err = LintProject(project.ProjectDir, langName, options)
+		} else {
+			violation = &LintViolation{Rule: "lint rules cannot be applied to this submission"}
+		}
+	} else {
+		if code == "" {
+			content, err := ioutil.ReadFile(session.CodeFile)
+			if err != nil {
+				judgeResult.JudgeResult = constants.JudgeFlagSE
+				judgeResult.SeInfo = err.Error()
+				return err
+			}
+			code = string(content)
+		}
+		violation, err = LintCode(code, langName, options)
+	}
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return err
+	}
+	if violation != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagFC
+		judgeResult.LintInfo = violation.Error()
+		session.Logger.Errorf("forbidden code: %s", violation.Error())
+		return violation
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path"
+)
+
+// 把提交答案题的输出文件包解压(或者把目录复制)到会话目录下
+func (session *JudgeSession) unpackSubmittedOutputs() error {
+	if session.CodeFile == "" {
+		return errors.Errorf("submission of output-only problems should be an archive or a directory")
+	}
+	outputDir := path.Join(session.SessionDir, "outputs")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	var err error
+	if utils.IsArchiveFile(session.CodeFile) {
+		err = utils.ExtractArchive(session.CodeFile, outputDir)
+	} else if s, e := os.Stat(session.CodeFile); e == nil && s.IsDir() {
+		err = utils.CopyDirectory(session.CodeFile, outputDir)
+	} else {
+		return errors.Errorf("submission of output-only problems should be an archive or a directory")
+	}
+	if err != nil {
+		return errors.Errorf("unpack submission error: %s", err.Error())
+	}
+	session.OutputDir = utils.UnwrapSingleDirectory(outputDir)
+	session.Logger.Infof("Output-only submission: %s", session.OutputDir)
+	return nil
+}
+
+// 把提交的<handle>.out作为这组测试数据的程序输出，没有提交则返回false
+func (session *JudgeSession) placeSubmittedOutput(rst *commonStructs.TestCaseResult) (bool, error) {
+	src, err := os.Open(path.Join(session.OutputDir, rst.Handle+".out"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path.Join(session.SessionDir, rst.ProgramOut), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer dst.Close()
+	if _, err = io.Copy(dst, src); err != nil {
+		return false, err
+	}
+	return true, nil
+}
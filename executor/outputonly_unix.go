@@ -0,0 +1,58 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+)
+
+// 提交答案题：用提交的输出文件代替程序输出，交给文本比较或者checker评测
+func (session *JudgeSession) judgeSubmittedOutput(judgeResult *commonStructs.TestCaseResult) {
+	ok, err := session.placeSubmittedOutput(judgeResult)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	if !ok {
+		judgeResult.JudgeResult = constants.JudgeFlagWA
+		judgeResult.Message = fmt.Sprintf("output file (%s.out) not submitted", judgeResult.Handle)
+		session.Logger.Warn(judgeResult.Message)
+		return
+	}
+	switch session.JudgeConfig.SpecialJudge.Mode {
+	case constants.SpecialJudgeModeDisabled:
+		judgeResult.JudgeResult = constants.JudgeFlagAC
+	case constants.SpecialJudgeModeChecker:
+		// 只运行checker
+		ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Checker)
+		defer cancel()
+		jinfo, err := runAsync(ctx, session, judgeResult, true)
+		if err != nil {
+			judgeResult.JudgeResult = constants.JudgeFlagSE
+			judgeResult.SeInfo = err.Error()
+			session.Logger.Error(err.Error())
+			return
+		}
+		session.saveExitRusage(judgeResult, jinfo, true)
+		session.analysisExitStatus(judgeResult, jinfo, true)
+		if judgeResult.JudgeResult != constants.JudgeFlagSpecialJudgeRequireChecker {
+			return
+		}
+	default:
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = "interactive and communication special judges are not supported for output-only problems"
+		session.Logger.Error(judgeResult.SeInfo)
+		return
+	}
+	session.Logger.Infof("Run text checker.")
+	err = session.DiffText(judgeResult)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"github.com/pkg/errors"
+	"os"
+	"path"
+)
+
+// IsProjectSubmission 判断提交的代码是否为多文件提交 (代码包或目录)
+func (session *JudgeSession) IsProjectSubmission() bool {
+	if session.CodeContent != "" || session.CodeFile == "" {
+		return false
+	}
+	if utils.IsArchiveFile(session.CodeFile) {
+		return true
+	}
+	s, err := os.Stat(session.CodeFile)
+	return err == nil && s.IsDir()
+}
+
+// 把提交的代码包解压(或者把目录复制)到会话目录下，返回项目根目录
+func (session *JudgeSession) unpackProject() (string, error) {
+	projectDir := path.Join(session.SessionDir, "project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return "", err
+	}
+	var err error
+	if utils.IsArchiveFile(session.CodeFile) {
+		err = utils.ExtractArchive(session.CodeFile, projectDir)
+	} else {
+		err = utils.CopyDirectory(session.CodeFile, projectDir)
+	}
+	if err != nil {
+		return "", errors.Errorf("unpack submission error: %s", err.Error())
+	}
+	return utils.UnwrapSingleDirectory(projectDir), nil
+}
+
+// 获取多文件提交的编译器提供程序
+func (session *JudgeSession) getProjectCompiler() (provider.CodeCompileProviderInterface, error) {
+	projectDir, err := session.unpackProject()
+	if err != nil {
+		return nil, err
+	}
+	options := session.JudgeConfig.Project
+	if options.BuildScript != "" {
+		options.BuildScript = path.Join(session.ConfigDir, options.BuildScript)
+		if _, err = os.Stat(options.BuildScript); err != nil {
+			return nil, errors.Errorf("build script (%s) not exists", session.JudgeConfig.Project.BuildScript)
+		}
+	}
+	compiler, err := provider.NewProjectCompileProvider(session.CodeLangName, projectDir, options)
+	if err != nil {
+		return nil, err
+	}
+	err = compiler.Init("", session.SessionDir)
+	if err != nil {
+		return nil, err
+	}
+	session.Logger.Infof("Multi-file submission: %s", compiler.String())
+	return compiler, nil
+}
@@ -15,7 +15,6 @@ import (
 	"path"
 	"path/filepath"
 	"syscall"
-	"time"
 )
 
 // PArgs Start Process Arguments
@@ -30,7 +29,10 @@ var ExtraEnviron = []string{"PYTHONIOENCODING=utf-8"}
 
 // 运行目标程序
 func (session *JudgeSession) runNormalJudge(rst *commonStructs.TestCaseResult) (*ProcessInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(session.Timeout)*time.Second)
+	if session.zygote != nil {
+		return session.runZygoteJudge(rst)
+	}
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
 	defer cancel()
 	return runAsync(ctx, session, rst, false)
 }
@@ -39,16 +41,14 @@ func (session *JudgeSession) runNormalJudge(rst *commonStructs.TestCaseResult) (
 func (session *JudgeSession) runSpecialJudge(rst *commonStructs.TestCaseResult) (*ProcessInfo, *ProcessInfo, error) {
 	if session.JudgeConfig.SpecialJudge.Mode == constants.SpecialJudgeModeChecker {
 
-		// checker模式，用runAsync依次运行
-		ctx1, cancel1 := context.WithTimeout(context.Background(), time.Duration(session.Timeout)*time.Second)
-		defer cancel1()
-		answer, err := runAsync(ctx1, session, rst, false)
+		// checker模式，依次运行目标程序和判题程序
+		answer, err := session.runNormalJudge(rst)
 		if err != nil {
 			return nil, nil, err
 		}
-		ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(session.Timeout)*time.Second)
-		defer cancel2()
-		checker, err := runAsync(ctx2, session, rst, true)
+		ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Checker)
+		defer cancel()
+		checker, err := runAsync(ctx, session, rst, true)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -56,7 +56,7 @@ func (session *JudgeSession) runSpecialJudge(rst *commonStructs.TestCaseResult)
 
 	} else if session.JudgeConfig.SpecialJudge.Mode == constants.SpecialJudgeModeInteractive {
 		// 交互模式
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(session.Timeout)*time.Second)
+		ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Interactor)
 		defer cancel()
 		return runInteractiveAsync(ctx, session, rst)
 	}
@@ -272,15 +272,6 @@ finish:
 // 运行一个新的进程
 func getProcessOptions(session *JudgeSession, rst *commonStructs.TestCaseResult, isChecker, pipeMode bool, pipeFd []uintptr) (*PArgs, error) {
 	var err error
-	// Get shell commands
-	commands := session.Commands
-	// 参考exec.Command，从环境变量获取编译器/VM真实的地址
-	programPath := commands[0]
-	if filepath.Base(programPath) == programPath {
-		if programPath, err = exec.LookPath(programPath); err != nil {
-			return nil, err
-		}
-	}
 	var infile, outfile, errfile string
 	var rlimit forkexec.ExecRLimit
 	var args []string
@@ -305,7 +296,15 @@ func getProcessOptions(session *JudgeSession, rst *commonStructs.TestCaseResult,
 		}
 		args = getSpecialJudgeArgs(session, rst)
 	} else {
-		execProgram = programPath
+		// Get shell commands
+		commands := session.Commands
+		// 参考exec.Command，从环境变量获取编译器/VM真实的地址
+		execProgram = commands[0]
+		if filepath.Base(execProgram) == execProgram {
+			if execProgram, err = exec.LookPath(execProgram); err != nil {
+				return nil, err
+			}
+		}
 		outfile = path.Join(session.SessionDir, rst.ProgramOut)
 		errfile = path.Join(session.SessionDir, rst.ProgramError)
 		rlimit = forkexec.ExecRLimit{
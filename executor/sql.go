@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SQL题中提交的查询语句和数据库文件的名称 (位于会话目录)
+const (
+	sqlSubmissionFile = "submission.sql"
+	sqlDatabaseFile   = "judge.db"
+)
+
+// 支持安全模式(-safe)的最低sqlite版本，安全模式下不能执行.shell、.system、ATTACH、readfile()等
+var sqlSafeModeVersion = [3]int{3, 37, 0}
+
+// SQLSubmissionError 提交的查询语句不符合要求 (判为编译错误)
+type SQLSubmissionError struct {
+	message string
+}
+
+// Error get error string
+func (e SQLSubmissionError) Error() string {
+	return e.message
+}
+
+// CheckSQLSubmission 检查提交的查询语句，不允许使用sqlite3命令行的点命令(如.shell、.system、.output、.read、.open)
+func CheckSQLSubmission(query []byte) error {
+	for i, line := range strings.Split(string(query), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ".") {
+			return SQLSubmissionError{message: fmt.Sprintf("line %d: dot-commands are not allowed: %s", i+1, strings.TrimSpace(line))}
+		}
+	}
+	return nil
+}
+
+// 检查sqlite引擎是否支持安全模式
+func checkSQLEngineVersion(engine string) error {
+	output, err := exec.Command(engine, "-version").Output()
+	if err != nil {
+		return errors.Errorf("get sqlite engine (%s) version error: %s", engine, err.Error())
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return errors.Errorf("unknown sqlite engine (%s) version", engine)
+	}
+	parts := strings.Split(fields[0], ".")
+	for i, least := range sqlSafeModeVersion {
+		value := 0
+		if i < len(parts) {
+			value, _ = strconv.Atoi(parts[i])
+		}
+		if value > least {
+			return nil
+		}
+		if value < least {
+			return errors.Errorf(
+				"sqlite engine (%s) version %s is too old, safe mode requires %d.%d.%d or later",
+				engine, fields[0], sqlSafeModeVersion[0], sqlSafeModeVersion[1], sqlSafeModeVersion[2],
+			)
+		}
+	}
+	return nil
+}
+
+// 解析sqlite3 -header -csv输出的查询结果，没有输出时表头和数据都为空
+func parseSQLResult(content []byte) ([]string, [][]string, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, nil, nil
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	return records[0], records[1:], nil
+}
+
+// 比较两个单元格，设置了误差时数值按绝对误差或相对误差比较
+func sqlCellEquals(expected, actual string, tolerance float64) bool {
+	if expected == actual {
+		return true
+	}
+	if tolerance <= 0 {
+		return false
+	}
+	e, err := strconv.ParseFloat(expected, 64)
+	if err != nil {
+		return false
+	}
+	a, err := strconv.ParseFloat(actual, 64)
+	if err != nil {
+		return false
+	}
+	diff := math.Abs(e - a)
+	return diff <= tolerance || diff <= tolerance*math.Abs(e)
+}
+
+func sqlRowEquals(expected, actual []string, tolerance float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if !sqlCellEquals(expected[i], actual[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareSQLResult 比较查询结果，两者都是带表头的CSV，返回是否一致以及不一致的原因
+func CompareSQLResult(expected, actual []byte, options commonStructs.SQLOptions) (bool, string, error) {
+	eHeader, eRows, err := parseSQLResult(expected)
+	if err != nil {
+		return false, "", errors.Errorf("parse expected result error: %s", err.Error())
+	}
+	aHeader, aRows, err := parseSQLResult(actual)
+	if err != nil {
+		return false, fmt.Sprintf("parse query result error: %s", err.Error()), nil
+	}
+	if len(eHeader) != len(aHeader) && (len(eRows) > 0 || len(aRows) > 0) {
+		return false, fmt.Sprintf("expect %d columns, got %d", len(eHeader), len(aHeader)), nil
+	}
+	if options.CheckColumnNames {
+		for i := range eHeader {
+			if i < len(aHeader) && eHeader[i] != aHeader[i] {
+				return false, fmt.Sprintf("column %d: expect name %s, got %s", i+1, eHeader[i], aHeader[i]), nil
+			}
+		}
+	}
+	if len(eRows) != len(aRows) {
+		return false, fmt.Sprintf("expect %d rows, got %d", len(eRows), len(aRows)), nil
+	}
+	if options.Ordered {
+		for i := range eRows {
+			if !sqlRowEquals(eRows[i], aRows[i], options.FloatTolerance) {
+				return false, fmt.Sprintf("row %d: expect %s, got %s", i+1, strings.Join(eRows[i], ","), strings.Join(aRows[i], ",")), nil
+			}
+		}
+		return true, "", nil
+	}
+	// 不考虑顺序时，先按文本完全相同的行匹配，剩下的行再按误差逐个匹配
+	remains := map[string]int{}
+	for _, row := range aRows {
+		remains[strings.Join(row, "\x00")]++
+	}
+	unmatched := make([][]string, 0)
+	for _, row := range eRows {
+		key := strings.Join(row, "\x00")
+		if remains[key] > 0 {
+			remains[key]--
+		} else {
+			unmatched = append(unmatched, row)
+		}
+	}
+	if len(unmatched) == 0 {
+		return true, "", nil
+	}
+	candidates := make([][]string, 0, len(unmatched))
+	for _, row := range aRows {
+		key := strings.Join(row, "\x00")
+		if remains[key] > 0 {
+			remains[key]--
+			candidates = append(candidates, row)
+		}
+	}
+	for _, row := range unmatched {
+		found := -1
+		for i, candidate := range candidates {
+			if candidate != nil && sqlRowEquals(row, candidate, options.FloatTolerance) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return false, fmt.Sprintf("expected row not found: %s", strings.Join(row, ",")), nil
+		}
+		candidates[found] = nil
+	}
+	return true, "", nil
+}
+
+// 准备SQL题的评测：保存提交的查询语句，并设置运行sqlite3的命令
+func (session *JudgeSession) prepareSQLJudge() error {
+	engine := session.JudgeConfig.SQL.Engine
+	if engine == "" {
+		engine = "sqlite3"
+	}
+	if _, err := exec.LookPath(engine); err != nil {
+		return errors.Errorf("sqlite engine (%s) not found: %s", engine, err.Error())
+	}
+	if err := checkSQLEngineVersion(engine); err != nil {
+		return err
+	}
+	query := []byte(session.CodeContent)
+	if session.CodeContent == "" {
+		var err error
+		query, err = ioutil.ReadFile(session.CodeFile)
+		if err != nil {
+			return errors.Errorf("read submission error: %s", err.Error())
+		}
+	}
+	if err := CheckSQLSubmission(query); err != nil {
+		return err
+	}
+	err := ioutil.WriteFile(path.Join(session.SessionDir, sqlSubmissionFile), query, 0644)
+	if err != nil {
+		return err
+	}
+	// 使用安全模式，遇到错误立即退出，并以带表头的CSV输出查询结果
+	session.Commands = []string{engine, "-safe", "-batch", "-bail", "-header", "-csv", path.Join(session.SessionDir, sqlDatabaseFile)}
+	return nil
+}
+
+// 删除上一组测试数据使用的数据库
+func (session *JudgeSession) resetSQLDatabase() error {
+	err := os.Remove(path.Join(session.SessionDir, sqlDatabaseFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+)
+
+// 读取sqlite3输出的错误信息 (最多1KB)
+func (session *JudgeSession) readSQLError(rst *commonStructs.TestCaseResult) string {
+	stderr, err := ioutil.ReadFile(path.Join(session.SessionDir, rst.ProgramError))
+	if err != nil {
+		return ""
+	}
+	if len(stderr) > 1024 {
+		stderr = stderr[:1024]
+	}
+	return string(stderr)
+}
+
+// 执行测试数据的建表脚本，生成新的数据库
+func (session *JudgeSession) setupSQLDatabase(judgeResult *commonStructs.TestCaseResult) error {
+	if err := session.resetSQLDatabase(); err != nil {
+		return err
+	}
+	setup := *judgeResult
+	setup.ProgramOut = judgeResult.Handle + "_setup.out"
+	setup.ProgramError = judgeResult.Handle + "_setup.err"
+	pinfo, err := session.runNormalJudge(&setup)
+	if err != nil {
+		return err
+	}
+	session.saveExitRusage(&setup, pinfo, false)
+	session.analysisExitStatus(&setup, pinfo, false)
+	if setup.JudgeResult != constants.JudgeFlagAC {
+		return errors.Errorf("test case (%s) input script failed: %s", judgeResult.Handle, constants.FlagMeansMap[setup.JudgeResult])
+	}
+	if msg := session.readSQLError(&setup); msg != "" {
+		return errors.Errorf("test case (%s) input script error: %s", judgeResult.Handle, msg)
+	}
+	return nil
+}
+
+// SQL题：用测试数据的脚本建立数据库，再执行提交的查询，比较查询结果
+func (session *JudgeSession) runSQLJudge(judgeResult *commonStructs.TestCaseResult) {
+	err := session.setupSQLDatabase(judgeResult)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	// 查询语句作为sqlite3的标准输入 (路径相对于题目目录)
+	query := *judgeResult
+	query.Input, err = filepath.Rel(session.ConfigDir, path.Join(session.SessionDir, sqlSubmissionFile))
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		return
+	}
+	pinfo, err := session.runNormalJudge(&query)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	session.saveExitRusage(judgeResult, pinfo, false)
+	session.analysisExitStatus(judgeResult, pinfo, false)
+	if judgeResult.JudgeResult != constants.JudgeFlagAC {
+		return
+	}
+	// 查询出错(如语法错误、表不存在)时判为运行错误
+	if msg := session.readSQLError(judgeResult); msg != "" {
+		judgeResult.JudgeResult = constants.JudgeFlagRE
+		judgeResult.ReInfo = msg
+		return
+	}
+
+	session.Logger.Infof("Run sql result checker.")
+	expected, err := ioutil.ReadFile(path.Join(session.ConfigDir, judgeResult.Output))
+	if err == nil {
+		var actual []byte
+		actual, err = ioutil.ReadFile(path.Join(session.SessionDir, judgeResult.ProgramOut))
+		if err == nil {
+			var same bool
+			var reason string
+			same, reason, err = CompareSQLResult(expected, actual, session.JudgeConfig.SQL)
+			if err == nil && !same {
+				judgeResult.JudgeResult = constants.JudgeFlagWA
+				judgeResult.TextDiffLog = fmt.Sprintf("result set mismatch: %s", reason)
+			}
+		}
+	}
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/pkg/errors"
+	"io"
+)
+
+// ErrStreamAborted 流式比较已经得到确定的结果，不再接收输出
+var ErrStreamAborted = errors.Errorf("stream comparison aborted")
+
+// StreamComparator 流式比较：程序运行时逐块读入输出，和答案同步比较(忽略空白字符，与DiffText的规则相同)
+// 只在结果确定时给出WA或OLE，其他情况(包括PE)仍然由程序结束后的DiffText判定
+type StreamComparator struct {
+	answer     *bufio.Reader
+	answerSize int64 // 答案文件大小
+	sizeLimit  int64 // 输出大小限制 (FileSizeLimit，0表示不限制)
+	answerPos  int64 // 答案已经读取的字节数
+	Written    int64 // 已经接收的输出字节数
+	Verdict    int   // 确定的结果 (JudgeFlagWA或JudgeFlagOLE)，没有确定时为0
+	Message    string
+}
+
+// NewStreamComparator 创建流式比较器
+func NewStreamComparator(answer io.Reader, answerSize int64, sizeLimit int64) *StreamComparator {
+	return &StreamComparator{
+		answer:     bufio.NewReader(answer),
+		answerSize: answerSize,
+		sizeLimit:  sizeLimit,
+	}
+}
+
+// 读取答案中的下一个非空白字符，答案已经读完时ok为false
+func (comparator *StreamComparator) nextAnswerByte() (ch byte, ok bool) {
+	for {
+		ch, err := comparator.answer.ReadByte()
+		if err != nil {
+			return 0, false
+		}
+		comparator.answerPos++
+		if !isSpaceChar(ch) {
+			return ch, true
+		}
+	}
+}
+
+// 给出确定的结果
+func (comparator *StreamComparator) abort(verdict int, message string) (int, error) {
+	comparator.Verdict = verdict
+	comparator.Message = message
+	return 0, ErrStreamAborted
+}
+
+// Write 接收一块输出，得到确定的结果后返回ErrStreamAborted
+func (comparator *StreamComparator) Write(p []byte) (int, error) {
+	if comparator.Verdict != 0 {
+		return 0, ErrStreamAborted
+	}
+	// 和DiffText一样，先检查大小：输出超过限制或达到答案的2倍时判为OLE
+	if written := comparator.Written + int64(len(p)); comparator.sizeLimit > 0 && written > comparator.sizeLimit {
+		comparator.Written = written
+		return comparator.abort(constants.JudgeFlagOLE, "WA: larger then limitation.")
+	} else if comparator.answerSize > 0 && written >= comparator.answerSize*2 {
+		comparator.Written = written
+		return comparator.abort(constants.JudgeFlagOLE, "WA: larger then 2 times.")
+	}
+	for i, ch := range p {
+		if isSpaceChar(ch) {
+			continue
+		}
+		expected, ok := comparator.nextAnswerByte()
+		if !ok || expected != ch {
+			comparator.Written += int64(i)
+			return comparator.abort(constants.JudgeFlagWA, fmt.Sprintf(
+				"WA: at leftPos=%d, rightPos=%d, leftByte=%d, rightByte=%d",
+				comparator.Written,
+				comparator.answerPos,
+				ch,
+				expected,
+			))
+		}
+	}
+	comparator.Written += int64(len(p))
+	return len(p), nil
+}
+
+// DiffLog 和DiffText格式相同的比较日志
+func (comparator *StreamComparator) DiffLog() string {
+	return fmt.Sprintf(
+		"tcLen=%d, ansLen=%d; %s (aborted while running)",
+		comparator.answerSize,
+		comparator.Written,
+		comparator.Message,
+	)
+}
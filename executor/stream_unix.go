@@ -0,0 +1,131 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/cmd"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"syscall"
+	"time"
+)
+
+// 目标程序退出后，等待读完管道中剩余输出的最长时间 (程序创建的子进程可能仍然持有管道)
+const streamDrainTimeout = time.Second
+
+// 是否使用流式比较 (预热模式下程序的输出由预热进程直接写入文件，不使用流式比较)
+func (session *JudgeSession) useStreamCompare() bool {
+	return session.JudgeConfig.StreamCompare && session.zygote == nil
+}
+
+// 运行目标程序，输出经过管道交给流式比较器，同时写入输出文件
+// 得到确定的WA或OLE时立即结束目标程序，此时输出文件只保存了已经读到的部分
+func (session *JudgeSession) runStreamJudge(rst *commonStructs.TestCaseResult) (*ProcessInfo, *StreamComparator, error) {
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
+	defer cancel()
+
+	answerFile := path.Join(session.ConfigDir, rst.Output)
+	answerInfo, err := os.Stat(answerFile)
+	if err != nil {
+		return nil, nil, errors.Errorf("get answer file info failed: %s", err.Error())
+	}
+	answer, err := os.Open(answerFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer answer.Close()
+	comparator := NewStreamComparator(answer, answerInfo.Size(), int64(session.JudgeConfig.FileSizeLimit))
+
+	pArgs, err := getProcessOptions(session, rst, false, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	outFile := pArgs.Attr.Files[1].(*os.File)
+	pipeRead, pipeWrite, err := os.Pipe()
+	if err != nil {
+		closeFiles(pArgs.Attr.Files)
+		return nil, nil, errors.Errorf("create pipe error: %s", err.Error())
+	}
+	pArgs.Attr.Files[1] = pipeWrite
+	defer closeFiles([]interface{}{outFile, pipeRead})
+
+	proc, err := cmd.StartProcess(pArgs.Name, pArgs.Args, pArgs.Attr)
+	// 只有目标程序持有管道的写入端，程序退出后才能读到EOF
+	closeFiles(pArgs.Attr.Files)
+	if err != nil {
+		return nil, nil, err
+	}
+	pinfo := ProcessInfo{Process: proc, Pid: proc.Pid}
+
+	// 读取输出并比较
+	streamDone := make(chan bool, 1)
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, rerr := pipeRead.Read(buf)
+			if n > 0 {
+				_, _ = outFile.Write(buf[:n])
+				if _, cerr := comparator.Write(buf[:n]); cerr != nil {
+					// 已经有确定的结果，不需要再运行下去
+					_ = syscall.Kill(proc.Pid, syscall.SIGKILL)
+					break
+				}
+			}
+			if rerr != nil {
+				if rerr != io.EOF {
+					log.Printf("[stream] read program output error: %s\n", rerr.Error())
+				}
+				break
+			}
+		}
+		streamDone <- true
+	}()
+
+	// 等待目标程序退出
+	exited := make(chan error, 1)
+	go func() {
+		pstate, werr := proc.Wait()
+		if werr == nil {
+			pinfo.Status = pstate.Sys().(syscall.WaitStatus)
+			pinfo.Rusage = pstate.SysUsage().(*syscall.Rusage)
+			if pinfo.Rusage == nil {
+				werr = errors.Errorf("get rusage failed")
+			}
+		}
+		exited <- werr
+	}()
+	select {
+	case err = <-exited:
+	case <-ctx.Done(): // 触发超时
+		log.Println("Child process timeout!")
+		_ = syscall.Kill(proc.Pid, syscall.SIGKILL)
+		<-exited
+		err = errors.Errorf("Child process timeout!")
+	}
+	select {
+	case <-streamDone:
+	case <-time.After(streamDrainTimeout):
+		// 关闭读取端使比较的协程退出
+		_ = pipeRead.Close()
+		<-streamDone
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pinfo, comparator, nil
+}
+
+// 使用流式比较得到的确定结果
+func (session *JudgeSession) applyStreamVerdict(rst *commonStructs.TestCaseResult, comparator *StreamComparator) {
+	rst.JudgeResult = comparator.Verdict
+	rst.TextDiffLog = comparator.DiffLog()
+	if comparator.Verdict == constants.JudgeFlagWA {
+		rst.SameLines, rst.TotalLines = lineDiff(session, rst)
+	}
+	session.Logger.Infof("Stream checker: %s", rst.TextDiffLog)
+}
@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/LanceLRQ/deer-executor/v2/common/logger"
@@ -23,14 +24,21 @@ type JudgeSession struct {
 	ConfigDir    string   // Config file dir
 	CodeLangName string   // Code file language name
 	CodeFile     string   // Code File Path
+	CodeContent  string   // Code content (optional, will be used instead of reading CodeFile)
 	LibraryDir   string   // Compile Library Path for Working Program
 	Commands     []string // Executable program commands
+	OutputDir    string   // Submitted output files directory (output-only problems)
 
 	JudgeConfig commonStructs.JudgeConfiguration      // Judge Configurations
 	Compiler    provider.CodeCompileProviderInterface // Compiler entity
 
-	Logger  *logger.JudgeLogger // Judge Logger
-	Timeout int                 // Process timeout (s)
+	Logger      *logger.JudgeLogger // Judge Logger
+	Timeout     int                 // Default process timeout (s), used when the timeouts of run/checker/interactor are not set
+	RunAllCases bool                // Don't stop at the first failed test case (for solutions verifying)
+	Zygote      bool                // Use warm workers to cut interpreter/JVM startup cost (zygote mode)
+
+	zygote *zygoteWorker   // Running warm worker (zygote mode)
+	budget context.Context // Total judge budget (cancelled when exceeded)
 }
 
 // SaveConfiguration 保存评测会话
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// SystemTimeouts 系统默认的各阶段超时设置，可以被系统配置(timeouts.json)替换，题目配置中的timeouts优先
+// 运行、checker、interactor没有设置时使用会话的Timeout；编译没有设置时只受语言的编译资源限制约束；总预算没有设置时不限制
+var SystemTimeouts = commonStructs.TimeoutOptions{
+	Generator: constants.TestlibGeneratorTimeLimit,
+	Validator: 5 * 1000,
+}
+
+// PlaceSystemTimeouts 替换系统默认的超时设置，配置文件中没有设置的项保留默认值
+func PlaceSystemTimeouts(configFile string) error {
+	if configFile != "" {
+		_, err := os.Stat(configFile)
+		// ignore
+		if os.IsNotExist(err) {
+			return nil
+		}
+		cbody, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+		timeouts := commonStructs.TimeoutOptions{}
+		err = json.Unmarshal(cbody, &timeouts)
+		if err != nil {
+			return err
+		}
+		SystemTimeouts = timeouts.Merge(SystemTimeouts)
+	}
+	return nil
+}
+
+// GetTimeouts 获取题目的各阶段超时设置，没有设置的项使用系统配置
+func GetTimeouts(config *commonStructs.JudgeConfiguration) commonStructs.TimeoutOptions {
+	return config.Timeouts.Merge(SystemTimeouts)
+}
+
+// 获取阶段的超时时间(ms)，没有设置时使用会话的Timeout
+func (session *JudgeSession) phaseTimeout(timeout int) time.Duration {
+	if timeout > 0 {
+		return time.Duration(timeout) * time.Millisecond
+	}
+	return time.Duration(session.Timeout) * time.Second
+}
+
+// 创建一个阶段的超时上下文，评测总预算耗尽时同样会被取消
+func (session *JudgeSession) phaseContext(timeout int) (context.Context, context.CancelFunc) {
+	parent := session.budget
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithTimeout(parent, session.phaseTimeout(timeout))
+}
+
+// 编译的超时时间(ms)，不超过评测总预算的剩余时间
+func (session *JudgeSession) compileTimeout() int {
+	timeout := GetTimeouts(&session.JudgeConfig).Compile
+	if session.budget != nil {
+		if deadline, ok := session.budget.Deadline(); ok {
+			remain := int(time.Until(deadline) / time.Millisecond)
+			if remain < 1 {
+				remain = 1
+			}
+			if timeout <= 0 || remain < timeout {
+				timeout = remain
+			}
+		}
+	}
+	return timeout
+}
+
+// 开始计算评测总预算，返回的函数用于结束计算
+func (session *JudgeSession) startBudget() func() {
+	total := GetTimeouts(&session.JudgeConfig).Total
+	if total <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(total)*time.Millisecond)
+	session.budget = ctx
+	return func() {
+		cancel()
+		session.budget = nil
+	}
+}
+
+// 评测总预算是否已经耗尽
+func (session *JudgeSession) isBudgetExceeded() bool {
+	return session.budget != nil && session.budget.Err() != nil
+}
+
+// 评测总预算耗尽，结束评测并标记结果为中止 (只保留已经完成的测试数据结果)
+func (session *JudgeSession) abortJudge(judgeResult *commonStructs.JudgeResult) {
+	judgeResult.Aborted = true
+	judgeResult.JudgeResult = constants.JudgeFlagSE
+	judgeResult.SeInfo = fmt.Sprintf("judge aborted: total time budget (%dms) exceeded", GetTimeouts(&session.JudgeConfig).Total)
+	session.Logger.Error(judgeResult.SeInfo)
+}
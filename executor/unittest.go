@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// UnitTestReportItem 单元测试报告中的一个测试
+type UnitTestReportItem struct {
+	Name    string  `json:"name"`    // Test name, e.g. "test_solution.test_add"
+	Status  string  `json:"status"`  // passed|failed|error|timeout|skipped
+	Message string  `json:"message"` // Failure message
+	Time    float64 `json:"time"`    // Time used (s)
+	Weight  int     `json:"weight"`  // Score weight (optional)
+}
+
+// 单元测试状态对应的评测结果
+var unitTestStatusMapping = map[string]int{
+	"passed":  constants.JudgeFlagAC,
+	"failed":  constants.JudgeFlagWA,
+	"skipped": constants.JudgeFlagWA,
+	"error":   constants.JudgeFlagRE,
+	"timeout": constants.JudgeFlagTLE,
+}
+
+// JUnit XML报告的结构，<testsuites>和<testsuite>都可以作为根节点
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	TestCases []junitTestCase  `xml:"testcase"`
+	Suites    []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (msg *junitMessage) String() string {
+	text := strings.TrimSpace(msg.Text)
+	if msg.Message == "" || text == "" {
+		return msg.Message + text
+	}
+	return msg.Message + "\n" + text
+}
+
+// 收集测试套件(包括嵌套的套件)中的全部测试
+func (suite *junitTestSuite) collect(items []UnitTestReportItem) []UnitTestReportItem {
+	for _, tc := range suite.TestCases {
+		item := UnitTestReportItem{Name: tc.Name, Status: "passed"}
+		if tc.ClassName != "" {
+			item.Name = tc.ClassName + "." + tc.Name
+		}
+		item.Time, _ = strconv.ParseFloat(strings.Replace(tc.Time, ",", "", -1), 64)
+		switch {
+		case tc.Error != nil:
+			item.Status, item.Message = "error", tc.Error.String()
+		case tc.Failure != nil:
+			item.Status, item.Message = "failed", tc.Failure.String()
+		case tc.Skipped != nil:
+			item.Status, item.Message = "skipped", tc.Skipped.String()
+		}
+		items = append(items, item)
+	}
+	for i := range suite.Suites {
+		items = suite.Suites[i].collect(items)
+	}
+	return items
+}
+
+// ParseUnitTestReport 解析单元测试报告，支持JUnit XML和JSON格式
+// JSON格式为：{"tests": [{"name": "...", "status": "passed|failed|error|timeout|skipped", "message": "...", "time": 0.1, "weight": 1}]}
+func ParseUnitTestReport(format string, content []byte) ([]UnitTestReportItem, error) {
+	var items []UnitTestReportItem
+	switch format {
+	case constants.UnitTestReportJUnit, "":
+		suite := junitTestSuite{}
+		if err := xml.Unmarshal(content, &suite); err != nil {
+			return nil, errors.Errorf("parse junit report error: %s", err.Error())
+		}
+		items = suite.collect(nil)
+	case constants.UnitTestReportJSON:
+		report := struct {
+			Tests []UnitTestReportItem `json:"tests"`
+		}{}
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, errors.Errorf("parse json report error: %s", err.Error())
+		}
+		items = report.Tests
+	default:
+		return nil, errors.Errorf("unsupported unit test report format: %s", format)
+	}
+	if len(items) == 0 {
+		return nil, errors.Errorf("no test found in unit test report")
+	}
+	for _, item := range items {
+		if _, ok := unitTestStatusMapping[item.Status]; !ok {
+			return nil, errors.Errorf("test (%s) has an unknown status: %s", item.Name, item.Status)
+		}
+	}
+	return items, nil
+}
+
+// 获取测试的分值权重，优先使用题目的设置，其次使用报告中的设置，默认为1
+func (session *JudgeSession) getUnitTestWeight(item UnitTestReportItem) int {
+	weights := session.JudgeConfig.UnitTest.Weights
+	if weight, ok := weights[item.Name]; ok {
+		return weight
+	}
+	if pos := strings.LastIndex(item.Name, "."); pos >= 0 {
+		if weight, ok := weights[item.Name[pos+1:]]; ok {
+			return weight
+		}
+	}
+	if item.Weight > 0 {
+		return item.Weight
+	}
+	return 1
+}
+
+// 读取测试程序输出的报告
+func (session *JudgeSession) readUnitTestReport(harness *commonStructs.TestCaseResult) ([]UnitTestReportItem, error) {
+	reportFile := path.Join(session.SessionDir, harness.ProgramOut)
+	if session.JudgeConfig.UnitTest.ReportFile != "" {
+		reportFile = path.Join(session.SessionDir, session.JudgeConfig.UnitTest.ReportFile)
+	}
+	content, err := ioutil.ReadFile(reportFile)
+	if err != nil {
+		return nil, errors.Errorf("read unit test report error: %s", err.Error())
+	}
+	return ParseUnitTestReport(session.JudgeConfig.UnitTest.ReportFormat, content)
+}
@@ -0,0 +1,104 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+)
+
+// 运行测试程序(harness)，只分析程序的运行状态，测试结果由报告决定
+func (session *JudgeSession) runUnitTestHarness(judgeResult *commonStructs.TestCaseResult) {
+	pinfo, err := session.runNormalJudge(judgeResult)
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		session.Logger.Error(err.Error())
+		return
+	}
+	session.AnalysisUnitTestHarness(judgeResult, pinfo)
+}
+
+// AnalysisUnitTestHarness 分析测试程序的运行状态
+// 测试框架在有测试失败时会以非0的退出代码结束，不视为运行错误；只有被信号结束(RE、TLE、MLE、OLE)或超出资源限制时才有结果
+func (session *JudgeSession) AnalysisUnitTestHarness(harness *commonStructs.TestCaseResult, pinfo *ProcessInfo) {
+	session.saveExitRusage(harness, pinfo, false)
+	session.analysisExitStatus(harness, pinfo, false)
+	if harness.JudgeResult == constants.JudgeFlagRE && pinfo.Status.Exited() {
+		harness.JudgeResult = constants.JudgeFlagAC
+		harness.ReInfo = ""
+	}
+}
+
+// 单元测试模式：运行一次测试程序，报告中的每个测试作为一组测试数据的结果
+func (session *JudgeSession) runUnitTestJudge(judgeResult *commonStructs.JudgeResult) {
+	harness := commonStructs.TestCaseResult{
+		Handle:       "unit_test",
+		ProgramOut:   "unit_test_program.out",
+		ProgramError: "unit_test_program.err",
+	}
+	// 测试程序的标准输入为空文件 (路径相对于题目目录)
+	stdin := path.Join(session.SessionDir, "unit_test.in")
+	err := ioutil.WriteFile(stdin, []byte{}, 0644)
+	if err == nil {
+		harness.Input, err = filepath.Rel(session.ConfigDir, stdin)
+	}
+	if err != nil {
+		judgeResult.JudgeResult = constants.JudgeFlagSE
+		judgeResult.SeInfo = err.Error()
+		return
+	}
+
+	session.Logger.Info("Run unit test harness")
+	session.JudgeOnce(&harness)
+	session.CollectUnitTestReport(judgeResult, &harness)
+}
+
+// CollectUnitTestReport 根据测试程序的运行结果和报告生成评测结果
+// 测试程序的标准错误输出(如unittest的运行摘要)不作为运行错误的依据，没有生成报告或报告无法解析时判为运行错误
+func (session *JudgeSession) CollectUnitTestReport(judgeResult *commonStructs.JudgeResult, harness *commonStructs.TestCaseResult) {
+	judgeResult.TimeUsed = harness.TimeUsed
+	judgeResult.MemoryUsed = harness.MemoryUsed
+	if harness.JudgeResult != constants.JudgeFlagAC {
+		judgeResult.JudgeResult = harness.JudgeResult
+		judgeResult.ReInfo = harness.ReInfo
+		judgeResult.SeInfo = harness.SeInfo
+		judgeResult.TestCases = append(judgeResult.TestCases, *harness)
+		return
+	}
+	items, err := session.readUnitTestReport(harness)
+	if err != nil {
+		// 没有生成报告，通常是提交的代码导致测试程序无法运行
+		stderr, _ := ioutil.ReadFile(path.Join(session.SessionDir, harness.ProgramError))
+		judgeResult.JudgeResult = constants.JudgeFlagRE
+		judgeResult.ReInfo = fmt.Sprintf("%s\n%s", err.Error(), string(stderr))
+		session.Logger.Error(err.Error())
+		return
+	}
+
+	judgeResult.JudgeResult = constants.JudgeFlagAC
+	for _, item := range items {
+		tcResult := commonStructs.TestCaseResult{
+			Handle:       item.Name,
+			ProgramOut:   harness.ProgramOut,
+			ProgramError: harness.ProgramError,
+			JudgeResult:  unitTestStatusMapping[item.Status],
+			TimeUsed:     int(item.Time * 1000),
+			Weight:       session.getUnitTestWeight(item),
+			Message:      item.Message,
+		}
+		judgeResult.FullScore += tcResult.Weight
+		if tcResult.JudgeResult == constants.JudgeFlagAC {
+			judgeResult.Score += tcResult.Weight
+		} else if judgeResult.JudgeResult == constants.JudgeFlagAC {
+			// 最终结果为第一个没有通过的测试的结果
+			judgeResult.JudgeResult = tcResult.JudgeResult
+		}
+		session.Logger.Infof("Test %s: %s", item.Name, item.Status)
+		judgeResult.TestCases = append(judgeResult.TestCases, tcResult)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"fmt"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
 	"github.com/LanceLRQ/deer-executor/v2/common/provider"
 	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
 	"github.com/pkg/errors"
@@ -61,6 +62,22 @@ func CheckRequireFilesExists(config *commonStructs.JudgeConfiguration, configDir
 			return errors.Errorf("special judge checker file (%s) not exists", config.SpecialJudge.Checker)
 		}
 	}
+	// 检查grader文件是否存在
+	for langName, grader := range config.Graders {
+		for _, file := range grader.Files {
+			_, err = os.Stat(path.Join(configDir, file))
+			if os.IsNotExist(err) {
+				return errors.Errorf("grader (%s) file (%s) not exists", langName, file)
+			}
+		}
+	}
+	// 检查代码填空的代码模板是否存在
+	for langName, file := range config.Problem.CodeTemplates {
+		_, err = os.Stat(path.Join(configDir, file))
+		if os.IsNotExist(err) {
+			return errors.Errorf("code template (%s) file (%s) not exists", langName, file)
+		}
+	}
 	// 检查每个测试数据里的文件是否存在
 	// 新版判题机要求无论有没有数据，都要有对应的输入输出文件。
 	// 但Testlib模式例外，因为数据是由generator自动生成的。
@@ -94,29 +111,56 @@ func GetOrCreateBinaryRoot(config *commonStructs.JudgeConfiguration) (string, er
 	return binRoot, nil
 }
 
-// CompileSpecialJudgeCodeFile 普通特殊评测的编译方法
-func CompileSpecialJudgeCodeFile(source, name, binRoot, configDir, libraryDir, lang string) (string, error) {
+// PlaceSystemConfiguration 载入当前目录下的系统配置文件 (compilers.json、jit_memory.json、languages.json、timeouts.json)
+// 所有命令行入口共用，文件不存在时使用默认设置
+func PlaceSystemConfiguration() error {
+	err := provider.PlaceCompilerCommands("./compilers.json")
+	if err != nil {
+		return err
+	}
+	err = constants.PlaceMemorySizeForJIT("./jit_memory.json")
+	if err != nil {
+		return err
+	}
+	err = provider.PlaceLanguages("./languages.json")
+	if err != nil {
+		return err
+	}
+	return PlaceSystemTimeouts("./timeouts.json")
+}
+
+// CompileSpecialJudgeCodeFile 普通特殊评测的编译方法，compileTimeout为编译的超时时间(ms)，0表示只受语言的编译资源限制约束
+func CompileSpecialJudgeCodeFile(source, name, binRoot, configDir, libraryDir, lang string, compileTimeout int) (string, error) {
 	genCodeFile := path.Join(configDir, source)
 	compileTarget := path.Join(binRoot, name)
 	_, err := os.Stat(genCodeFile)
 	if err != nil && os.IsNotExist(err) {
 		return compileTarget, errors.Errorf("checker source code file not exists")
 	}
-	var ok bool
-	var ceinfo string
-	switch lang {
-	case "c", "gcc", "gnu-c":
-		compiler := provider.NewGnucppCompileProvider()
-		ok, ceinfo = compiler.ManualCompile(genCodeFile, compileTarget, []string{libraryDir})
-	case "go", "golang":
-		compiler := provider.NewGolangCompileProvider()
-		ok, ceinfo = compiler.ManualCompile(genCodeFile, compileTarget)
-	case "cpp", "gcc-cpp", "gcpp", "g++", "":
-		compiler := provider.NewGnucppCompileProvider()
-		ok, ceinfo = compiler.ManualCompile(genCodeFile, compileTarget, []string{libraryDir})
+	if lang == "" {
+		lang = "g++"
+	}
+	compiler, err := provider.NewCompileProvider(lang)
+	if err != nil {
+		return compileTarget, errors.Errorf("checker must be written by c/c++/golang")
+	}
+	var libraryDirs []string
+	switch provider.GetLanguageFamily(compiler.Name) {
+	case "gcc":
+		// c语言的checker也使用g++编译
+		compiler, err = provider.NewCompileProvider("g++")
+		if err != nil {
+			return compileTarget, err
+		}
+		libraryDirs = []string{libraryDir}
+	case "g++":
+		libraryDirs = []string{libraryDir}
+	case "golang":
 	default:
 		return compileTarget, errors.Errorf("checker must be written by c/c++/golang")
 	}
+	compiler.SetCompileTimeout(compileTimeout)
+	ok, ceinfo := compiler.ManualCompile(genCodeFile, compileTarget, libraryDirs)
 	if ok {
 		return compileTarget, nil
 	}
@@ -0,0 +1,19 @@
+package executor
+
+import (
+	"bufio"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/cmd"
+	"os"
+)
+
+// 预热阶段额外允许的CPU时间(ms)，用于生成启动缓存(如JVM的类数据共享归档)
+const warmupExtraTimeLimit = 5000
+
+// 预热进程(zygote)：在沙箱中启动一次解释器，之后每组测试数据由它fork一个干净的子进程运行代码
+type zygoteWorker struct {
+	pid          int
+	process      *cmd.Process
+	request      *os.File      // 请求管道 (写入端)
+	responseFile *os.File      // 回复管道 (读取端)
+	response     *bufio.Reader // 回复管道的读取缓冲
+}
@@ -0,0 +1,385 @@
+// +build linux darwin
+
+package executor
+
+import (
+	"bufio"
+	"context"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/cmd"
+	"github.com/LanceLRQ/deer-executor/v2/common/sandbox/forkexec"
+	commonStructs "github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// 预热进程没有在限定时间内回复
+var errZygoteTimeout = errors.Errorf("Child process timeout!")
+
+// 按真实时间限制结束子进程后，等待预热进程回复运行结果的时间
+const zygoteKillWait = time.Second
+
+// 启动预热：支持fork的解释器启动一个预热进程，JVM语言运行一次预热命令生成启动缓存
+// 预热失败时只记录警告，评测会使用冷启动的方式继续进行
+func (session *JudgeSession) startZygote(judgeResult *commonStructs.JudgeResult) {
+	if !session.Zygote || session.Compiler == nil {
+		return
+	}
+	problemType := session.JudgeConfig.Problem.ProblemType
+	mode := session.JudgeConfig.SpecialJudge.Mode
+	if (problemType != constants.ProblemTypeNormal && problemType != constants.ProblemTypeCodeFill) ||
+		(mode != constants.SpecialJudgeModeDisabled && mode != constants.SpecialJudgeModeChecker) {
+		session.Logger.Warn("Zygote mode only supports normal and checker judgement, use cold start.")
+		return
+	}
+	startTime := time.Now()
+	zygoteArgs, err := session.Compiler.GetZygoteArgs()
+	if err != nil {
+		session.Logger.Warnf("Prepare zygote worker error: %s, use cold start.", err.Error())
+		return
+	}
+	if zygoteArgs != nil {
+		worker, ready, err := session.startZygoteWorker(zygoteArgs)
+		if err != nil {
+			session.Logger.Warnf("Start zygote worker error: %s, use cold start.", err.Error())
+			return
+		}
+		session.zygote = worker
+		judgeResult.StartupTime = int(time.Since(startTime) / time.Millisecond)
+		session.Logger.Infof(
+			"Zygote worker (%d) is ready, startup time: %d ms (cpu: %d ms).",
+			worker.pid,
+			judgeResult.StartupTime,
+			ready.Startup/1000,
+		)
+		if session.JudgeConfig.StreamCompare {
+			session.Logger.Warn("Stream comparison is not used in zygote mode.")
+		}
+		return
+	}
+	warmup, warmRun, cache := session.Compiler.GetWarmupArgs()
+	if warmup != nil {
+		if err = session.runWarmup(warmup); err != nil {
+			session.Logger.Warnf("Warmup error: %s, use cold start.", err.Error())
+			return
+		}
+		if _, err = os.Stat(cache); err != nil {
+			session.Logger.Warnf("Warmup cache (%s) not created, use cold start.", path.Base(cache))
+			return
+		}
+		session.Commands = warmRun
+		judgeResult.StartupTime = int(time.Since(startTime) / time.Millisecond)
+		session.Logger.Infof("Warmup finished in %d ms.", judgeResult.StartupTime)
+		return
+	}
+	session.Logger.Warnf("Language (%s) does not support zygote mode, use cold start.", session.Compiler.GetName())
+}
+
+// StopZygote 结束预热进程 (RunJudge结束时会自动调用，单独使用PrepareJudge时需要手动调用)
+func (session *JudgeSession) StopZygote() {
+	if session.zygote == nil {
+		return
+	}
+	session.zygote.stop()
+	session.zygote = nil
+}
+
+// 从环境变量中查找程序的真实地址
+func lookExecProgram(name string) (string, error) {
+	if filepath.Base(name) == name {
+		return exec.LookPath(name)
+	}
+	return name, nil
+}
+
+// 启动预热进程并等待它就绪
+// 预热进程本身只限制文件大小，资源限制由子进程在运行代码前设置；它和子进程在同一个进程组中，结束时一起被清理
+func (session *JudgeSession) startZygoteWorker(args []string) (*zygoteWorker, *provider.ZygoteResponse, error) {
+	execProgram, err := lookExecProgram(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	requestRead, requestWrite, err := os.Pipe()
+	if err != nil {
+		return nil, nil, errors.Errorf("create pipe error: %s", err.Error())
+	}
+	responseRead, responseWrite, err := os.Pipe()
+	if err != nil {
+		closeFiles([]interface{}{requestRead, requestWrite})
+		return nil, nil, errors.Errorf("create pipe error: %s", err.Error())
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		closeFiles([]interface{}{requestRead, requestWrite, responseRead, responseWrite})
+		return nil, nil, err
+	}
+	stderr, err := os.OpenFile(path.Join(session.SessionDir, "zygote.err"), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		closeFiles([]interface{}{requestRead, requestWrite, responseRead, responseWrite, devNull})
+		return nil, nil, err
+	}
+	// 控制管道需要放在预热进程约定的文件描述符上
+	files := make([]interface{}, provider.ZygoteResponseFd+1)
+	files[0], files[1], files[2] = devNull, devNull, stderr
+	files[provider.ZygoteRequestFd] = requestRead
+	files[provider.ZygoteResponseFd] = responseWrite
+
+	proc, err := cmd.StartProcess(execProgram, args, &cmd.ProcAttr{
+		Dir:   session.SessionDir,
+		Env:   append(os.Environ(), ExtraEnviron...),
+		Files: files,
+		Sys: &forkexec.SysProcAttr{
+			Setpgid: true,
+			Rlimit: forkexec.ExecRLimit{
+				FileSizeLimit: session.JudgeConfig.FileSizeLimit,
+			},
+		},
+	})
+	closeFiles(files)
+	if err != nil {
+		closeFiles([]interface{}{requestWrite, responseRead})
+		return nil, nil, err
+	}
+	worker := &zygoteWorker{
+		pid:          proc.Pid,
+		process:      proc,
+		request:      requestWrite,
+		responseFile: responseRead,
+		response:     bufio.NewReader(responseRead),
+	}
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
+	defer cancel()
+	ready, err := worker.receive(ctx, "")
+	if err == nil && !ready.Ready {
+		err = errors.Errorf("unexpected zygote response")
+	}
+	if err != nil {
+		worker.stop()
+		return nil, nil, err
+	}
+	return worker, ready, nil
+}
+
+// 预热进程的一个回复
+type zygoteReceived struct {
+	response *provider.ZygoteResponse
+	err      error
+}
+
+// 在后台读取预热进程的下一个回复，nonce不为空时检查回复是否属于当前的请求
+func (worker *zygoteWorker) next(nonce string) <-chan zygoteReceived {
+	ch := make(chan zygoteReceived, 1)
+	go func() {
+		response, err := provider.ReadZygoteResponse(worker.response)
+		if err == nil && response.Nonce != nonce {
+			response, err = nil, errors.Errorf("unexpected zygote response")
+		}
+		ch <- zygoteReceived{response, err}
+	}()
+	return ch
+}
+
+// 读取预热进程的回复，超时的时候结束整个进程组
+func (worker *zygoteWorker) receive(ctx context.Context, nonce string) (*provider.ZygoteResponse, error) {
+	select {
+	case rel := <-worker.next(nonce):
+		return rel.response, rel.err
+	case <-ctx.Done():
+		worker.kill()
+		return nil, errZygoteTimeout
+	}
+}
+
+// 结束预热进程及其子进程
+func (worker *zygoteWorker) kill() {
+	_ = syscall.Kill(-worker.pid, syscall.SIGKILL)
+}
+
+// 关闭请求管道，预热进程读到EOF后自行退出
+func (worker *zygoteWorker) stop() {
+	_ = worker.request.Close()
+	exited := make(chan bool, 1)
+	go func() {
+		_, _ = worker.process.Wait()
+		exited <- true
+	}()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		worker.kill()
+		<-exited
+	}
+	_ = worker.responseFile.Close()
+}
+
+// 在预热进程中运行目标程序，预热进程异常退出时改为冷启动
+func (session *JudgeSession) runZygoteJudge(rst *commonStructs.TestCaseResult) (*ProcessInfo, error) {
+	worker := session.zygote
+	request := provider.ZygoteRequest{
+		Nonce:         uuid.NewV4().String(),
+		Stdin:         path.Join(session.ConfigDir, rst.Input),
+		Stdout:        path.Join(session.SessionDir, rst.ProgramOut),
+		Stderr:        path.Join(session.SessionDir, rst.ProgramError),
+		TimeLimit:     session.JudgeConfig.TimeLimit,
+		RealTimeLimit: session.JudgeConfig.RealTimeLimit,
+		MemoryLimit:   session.JudgeConfig.MemoryLimit,
+		FileSizeLimit: session.JudgeConfig.FileSizeLimit,
+	}
+	err := provider.WriteZygoteRequest(worker.request, &request)
+	if err == nil {
+		var pinfo *ProcessInfo
+		pinfo, err = session.waitZygoteCase(rst, request.Nonce)
+		if err == nil {
+			return pinfo, nil
+		}
+	}
+	session.StopZygote()
+	if err == errZygoteTimeout {
+		return nil, err
+	}
+	session.Logger.Warnf("Zygote worker exited unexpectedly: %s, use cold start.", err.Error())
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
+	defer cancel()
+	return runAsync(ctx, session, rst, false)
+}
+
+// 等待预热进程运行完一组测试数据：先收到子进程的pid，再收到运行结果
+// 真实时间限制由评测端计时(子进程自己设置的定时器可以被代码取消)，超时时结束子进程，和冷启动时一样视为被SIGALRM结束
+func (session *JudgeSession) waitZygoteCase(rst *commonStructs.TestCaseResult, nonce string) (*ProcessInfo, error) {
+	worker := session.zygote
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
+	defer cancel()
+	started, err := worker.receive(ctx, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if started.Pid <= 0 {
+		return nil, errors.Errorf("unexpected zygote response")
+	}
+	startTime := time.Now()
+	var realTimeout <-chan time.Time
+	if session.JudgeConfig.RealTimeLimit > 0 {
+		timer := time.NewTimer(time.Duration(session.JudgeConfig.RealTimeLimit) * time.Millisecond)
+		defer timer.Stop()
+		realTimeout = timer.C
+	}
+	finished := worker.next(nonce)
+	select {
+	case rel := <-finished:
+		if rel.err != nil {
+			return nil, rel.err
+		}
+		return zygoteProcessInfo(rst, rel.response), nil
+	case <-realTimeout:
+	case <-ctx.Done():
+		_ = syscall.Kill(started.Pid, syscall.SIGKILL)
+		worker.kill()
+		return nil, errZygoteTimeout
+	}
+	_ = syscall.Kill(started.Pid, syscall.SIGKILL)
+	pinfo := &ProcessInfo{Rusage: &syscall.Rusage{Utime: syscall.NsecToTimeval(time.Since(startTime).Nanoseconds())}}
+	select {
+	case rel := <-finished:
+		if rel.err != nil {
+			return nil, rel.err
+		}
+		pinfo = zygoteProcessInfo(rst, rel.response)
+	case <-time.After(zygoteKillWait):
+		// 预热进程没有回复(可能被子进程暂停了)，之后的测试数据改为冷启动
+		session.Logger.Warn("Zygote worker does not respond, use cold start.")
+		session.StopZygote()
+	}
+	pinfo.Status = syscall.WaitStatus(syscall.SIGALRM)
+	return pinfo, nil
+}
+
+// 把预热进程回复的子进程信息转换为ProcessInfo，启动代码前用掉的CPU时间单独记录，不计入运行时间
+func zygoteProcessInfo(rst *commonStructs.TestCaseResult, response *provider.ZygoteResponse) *ProcessInfo {
+	userTime := response.UserTime - response.Startup
+	if userTime < 0 {
+		userTime = 0
+	}
+	rst.StartupTime = int(response.Startup / 1000)
+	return &ProcessInfo{
+		Status: syscall.WaitStatus(response.Status),
+		Rusage: &syscall.Rusage{
+			Utime:  syscall.NsecToTimeval(userTime * 1000),
+			Stime:  syscall.NsecToTimeval(response.SystemTime * 1000),
+			Minflt: response.Minflt,
+			Maxrss: response.MaxRSS,
+		},
+	}
+}
+
+// 使用第一组测试数据运行一次预热命令 (输出会被丢弃)，资源限制和评测时相同，并额外允许生成缓存的时间
+func (session *JudgeSession) runWarmup(args []string) error {
+	execProgram, err := lookExecProgram(args[0])
+	if err != nil {
+		return err
+	}
+	infile := os.DevNull
+	if len(session.JudgeConfig.TestCases) > 0 {
+		infile = path.Join(session.ConfigDir, session.JudgeConfig.TestCases[0].Input)
+	}
+	stdin, err := os.OpenFile(infile, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	stdout, err := os.OpenFile(path.Join(session.SessionDir, "warmup.out"), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		closeFiles([]interface{}{stdin})
+		return err
+	}
+	stderr, err := os.OpenFile(path.Join(session.SessionDir, "warmup.err"), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		closeFiles([]interface{}{stdin, stdout})
+		return err
+	}
+	files := []interface{}{stdin, stdout, stderr}
+	defer closeFiles(files)
+
+	proc, err := cmd.StartProcess(execProgram, args, &cmd.ProcAttr{
+		Dir:   session.SessionDir,
+		Env:   append(os.Environ(), ExtraEnviron...),
+		Files: files,
+		Sys: &forkexec.SysProcAttr{
+			Rlimit: forkexec.ExecRLimit{
+				TimeLimit:     session.JudgeConfig.TimeLimit + warmupExtraTimeLimit,
+				MemoryLimit:   session.JudgeConfig.MemoryLimit,
+				FileSizeLimit: session.JudgeConfig.FileSizeLimit,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := session.phaseContext(GetTimeouts(&session.JudgeConfig).Run)
+	defer cancel()
+	exited := make(chan *cmd.ProcessState, 1)
+	go func() {
+		pstate, _ := proc.Wait()
+		exited <- pstate
+	}()
+	select {
+	case pstate := <-exited:
+		if pstate == nil {
+			return errors.Errorf("wait warmup process error")
+		}
+		// 预热只需要生成缓存，程序的退出代码不影响结果；被信号结束(如超时)时缓存不会生成
+		if status := pstate.Sys().(syscall.WaitStatus); status.Signaled() {
+			return errors.Errorf("warmup process killed by signal: %d", status.Signal())
+		}
+		return nil
+	case <-ctx.Done():
+		_ = syscall.Kill(proc.Pid, syscall.SIGKILL)
+		<-exited
+		return errors.Errorf("warmup process timeout")
+	}
+}
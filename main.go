@@ -5,6 +5,7 @@ import (
 	"github.com/LanceLRQ/deer-executor/v2/client"
 	"github.com/LanceLRQ/deer-executor/v2/client/generate"
 	"github.com/LanceLRQ/deer-executor/v2/client/run"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
@@ -23,6 +24,10 @@ func main() {
 		HelpName: "deer-executor",
 		Usage:    "An executor for online judge.",
 		Writer:   os.Stderr,
+		// 所有命令共用的系统配置 (语言注册表、超时设置等)
+		Before: func(c *cli.Context) error {
+			return executor.PlaceSystemConfiguration()
+		},
 		Action: func(c *cli.Context) error {
 			fmt.Println("Deer Executor\n--------------------")
 			fmt.Printf("version: %s (built %s)\n", buildVersion, buildTime)
@@ -39,6 +44,17 @@ func main() {
 				Action:    run.UserRunJudge,
 				Flags:     client.RunFlags,
 			},
+			{
+				Name:   "languages",
+				Usage:  "list registered languages",
+				Action: run.ListLanguages,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "probe",
+						Usage: "probe compiler/interpreter versions",
+					},
+				},
+			},
 			{
 				Name:        "example",
 				Aliases:     []string{"e"},
@@ -70,6 +86,14 @@ func main() {
 				Usage:       "problem manager",
 				Subcommands: client.AppProblemSubCommands,
 			},
+			{
+				Name:      "similarity",
+				Aliases:   []string{"sim"},
+				Usage:     "detect similar submissions (plagiarism check)",
+				ArgsUsage: "<submission_dir|code_file|result_file>...",
+				Action:    client.RunSimilarity,
+				Flags:     client.SimilarityFlags,
+			},
 			{
 				Name:   "test",
 				Hidden: true,
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/client"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test: build cache stores artifacts by key and evicts the least recently used ones
+func TestBuildCache(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-cache")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	cache := &provider.BuildCache{Dir: path.Join(workDir, "cache"), MaxSize: 2500}
+	if cache.Key("g++", "a", "b") == cache.Key("g++", "ab", "") {
+		t.Fatal("keys of different parts should not be equal")
+		return
+	}
+
+	artifact := path.Join(workDir, "a.out")
+	keys := []string{cache.Key("1"), cache.Key("2"), cache.Key("3")}
+	for i, key := range keys {
+		if err = ioutil.WriteFile(artifact, []byte(strings.Repeat("x", 1000)), 0755); err != nil {
+			t.Fatal(err)
+			return
+		}
+		if err = cache.Store(key, artifact); err != nil {
+			t.Fatal(err)
+			return
+		}
+		// 第一个缓存在第二个之后被使用过，淘汰时应该保留
+		if i == 1 {
+			time.Sleep(10 * time.Millisecond)
+			if !cache.Load(keys[0], path.Join(workDir, "loaded")) {
+				t.Fatal("cache should be hit")
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	target := path.Join(workDir, "target")
+	if !cache.Load(keys[0], target) || !cache.Load(keys[2], target) {
+		t.Fatal("recently used artifacts should be kept")
+		return
+	}
+	if cache.Load(keys[1], target) {
+		t.Fatal("least recently used artifact should be evicted")
+		return
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode()&0100 == 0 {
+		t.Fatal("loaded artifact should be executable")
+	}
+}
+
+// Test: build cache refuses a directory writable by other users
+func TestBuildCacheDirPermission(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-cache")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+	defer provider.SetBuildCache("", 0)
+
+	shared := path.Join(workDir, "shared")
+	_ = os.Mkdir(shared, 0777)
+	_ = os.Chmod(shared, 0777)
+	if err = provider.SetBuildCache(shared, 1024); err == nil {
+		t.Fatal("world-writable cache dir should be refused")
+		return
+	}
+	private := path.Join(workDir, "private")
+	if err = provider.SetBuildCache(private, 1024); err != nil {
+		t.Fatal(err)
+		return
+	}
+	info, err := os.Stat(private)
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("cache dir should be created with 0700: %v, %v", info, err)
+	}
+}
+
+// Test: a second "problem build" of unchanged sources is served from the build cache
+func TestProblemBuildCache(t *testing.T) {
+	if _, err := exec.LookPath("g++"); err != nil {
+		t.Skip("g++ not found")
+		return
+	}
+	workDir, err := ioutil.TempDir("", "deer-cache")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+	defer provider.SetBuildCache("", 0)
+
+	configFile := path.Join(workDir, "problem.json")
+	_ = ioutil.WriteFile(configFile, []byte(`{"testlib": {"generators": [{"name": "gen", "source": "gen.cpp"}]}}`), 0644)
+	_ = ioutil.WriteFile(path.Join(workDir, "gen.cpp"), []byte("int main() { return 0; }\n"), 0644)
+	cacheDir, libDir := path.Join(workDir, "cache"), path.Join(workDir, "lib")
+	_ = os.Mkdir(libDir, 0755)
+	app := &cli.App{Commands: client.AppProblemSubCommands}
+	args := []string{"problem", "build", "--library", libDir, "--build-cache", cacheDir, configFile}
+	if err = app.Run(args); err != nil {
+		t.Fatal(err)
+		return
+	}
+	target := path.Join(workDir, "bin", "g_gen")
+	if _, err = os.Stat(target); err != nil {
+		t.Fatalf("generator should be built: %v", err)
+		return
+	}
+	// 在缓存的编译产物末尾加上标记，命中缓存时编译结果会带有这个标记
+	marker := []byte("cached-artifact")
+	entries, _ := filepath.Glob(path.Join(cacheDir, "*", "*"))
+	for _, entry := range entries {
+		if info, err := os.Stat(entry); err == nil && info.Mode().IsRegular() {
+			fp, _ := os.OpenFile(entry, os.O_APPEND|os.O_WRONLY, 0)
+			_, _ = fp.Write(marker)
+			_ = fp.Close()
+		}
+	}
+	if err = app.Run(args); err != nil {
+		t.Fatal(err)
+		return
+	}
+	content, err := ioutil.ReadFile(target)
+	if err != nil || !strings.HasSuffix(string(content), string(marker)) {
+		t.Fatalf("second build should be a cache hit: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"testing"
+)
+
+// Test: insert answers into the code template
+func TestFillCodeTemplate(t *testing.T) {
+	template := "#include <stdio.h>\nint main() {\n    int a, b;\n    return 0;\n}"
+	demoCases := map[string]structs.JudgeDemoCase{
+		"read":  {Handle: "read", Line: 3, Order: 1, MaxLength: 64},
+		"print": {Handle: "print", Line: 3, Order: 2, ForbiddenTokens: []string{"system", "#include"}},
+		"head":  {Handle: "head", Line: 1, Lines: map[string]int{"g++": 0}},
+	}
+	answers := map[string]string{
+		"read":  "    scanf(\"%d%d\", &a, &b);",
+		"print": "    printf(\"%d\\n\", a + b);\n",
+		"head":  "// filled",
+	}
+	code, err := executor.FillCodeTemplate(template, "gcc", demoCases, answers)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expect := "#include <stdio.h>\n// filled\nint main() {\n    int a, b;\n" +
+		"    scanf(\"%d%d\", &a, &b);\n    printf(\"%d\\n\", a + b);\n    return 0;\n}"
+	if code != expect {
+		t.Fatalf("unexpected code:\n%s", code)
+		return
+	}
+	// 按语言设置的插入位置
+	code, err = executor.FillCodeTemplate(template, "g++17", demoCases, map[string]string{"head": "// filled"})
+	if err != nil || code[:9] != "// filled" {
+		t.Fatalf("language line should be used: %v\n%s", err, code)
+		return
+	}
+
+	cases := []map[string]string{
+		{"print": "system(\"ls\");"},
+		{"read": string(make([]byte, 65))},
+		{"unknown": ""},
+	}
+	for _, item := range cases {
+		_, err = executor.FillCodeTemplate(template, "gcc", demoCases, item)
+		if _, ok := err.(executor.CodeFillError); !ok {
+			t.Fatalf("answer %v should be rejected, got: %v", item, err)
+			return
+		}
+	}
+	if _, err = executor.FillCodeTemplate(template, "gcc", demoCases, map[string]string{"print": "systematic();"}); err != nil {
+		t.Fatal("identifier tokens should match whole words only")
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"testing"
+)
+
+// Test: build the pipe layout of communication problems
+func TestCommunicationTopology(t *testing.T) {
+	topology, err := executor.BuildCommunicationTopology(structs.CommunicationOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(topology.Instances) != 2 || len(topology.Channels) != 4 {
+		t.Fatalf("unexpected default topology: %+v", topology)
+		return
+	}
+	// 默认布局：manager的fd 3+2i写入实例i，fd 4+2i读取实例i
+	manager := topology.Endpoints["manager"]
+	if len(manager) != 4 || !manager[0].Write || manager[1].Write || manager[2].Channel != 2 {
+		t.Fatalf("unexpected manager endpoints: %+v", manager)
+		return
+	}
+
+	// encoder的输出经过manager转发给decoder，decoder还能直接读取encoder的第二个输出
+	topology, err = executor.BuildCommunicationTopology(structs.CommunicationOptions{
+		Roles: []string{"encoder", "decoder"},
+		Channels: []structs.CommunicationChannel{
+			{From: "manager", To: "encoder"},
+			{From: "encoder", To: "manager"},
+			{From: "manager", To: "decoder"},
+			{From: "decoder", To: "manager"},
+			{From: "encoder", To: "decoder"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	encoder, decoder := topology.Endpoints["encoder"], topology.Endpoints["decoder"]
+	if len(encoder) != 3 || encoder[0].Channel != 0 || encoder[1].Channel != 1 || encoder[2].Channel != 4 || !encoder[2].Write {
+		t.Fatalf("unexpected encoder endpoints: %+v", encoder)
+		return
+	}
+	if len(decoder) != 3 || decoder[0].Channel != 2 || decoder[2].Channel != 4 || decoder[2].Write {
+		t.Fatalf("unexpected decoder endpoints: %+v", decoder)
+		return
+	}
+
+	invalid := []structs.CommunicationOptions{
+		{Instances: 3, Roles: []string{"a", "b"}},
+		{Roles: []string{"manager", "b"}},
+		{Roles: []string{"a", "b"}, Channels: []structs.CommunicationChannel{{From: "a", To: "c"}}},
+		{Roles: []string{"a", "b"}, Channels: []structs.CommunicationChannel{{From: "manager", To: "a"}, {From: "a", To: "manager"}}},
+	}
+	for _, options := range invalid {
+		if _, err = executor.BuildCommunicationTopology(options); err == nil {
+			t.Fatalf("options should be rejected: %+v", options)
+			return
+		}
+	}
+}
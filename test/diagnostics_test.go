@@ -0,0 +1,47 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"strings"
+	"testing"
+)
+
+// Test: parse compiler diagnostics and hide the host paths
+func TestParseCompileDiagnostics(t *testing.T) {
+	workDir := "/tmp/deer/session/4b1f6a7e-55b4-4c1e-9f2a-0e7d51c0a1b2"
+	source := workDir + "/0c6f3e55-8a0d-4f8e-a3a1-8c2f4e9b7d10.cpp"
+	output := source + ": In function 'int main()':\n" +
+		source + ":5:9: warning: unused variable 'x' [-Wunused-variable]\n" +
+		source + ":7:5: error: 'cot' was not declared in this scope\n" +
+		"Main.java:3: error: ';' expected\n" +
+		"error[E0425]: cannot find value `y` in this scope\n" +
+		" --> src/main.rs:4:13\n" +
+		"  |\n" +
+		"error: aborting due to previous error\n" +
+		"./prog.go:6:2: undefined: fmtt\n"
+
+	sanitized := provider.SanitizeCompileOutput(output, source, workDir, "main.cpp")
+	if strings.Contains(sanitized, "/tmp/deer") {
+		t.Fatalf("host path not removed:\n%s", sanitized)
+		return
+	}
+	diagnostics := provider.ParseCompileDiagnostics(sanitized)
+	expected := []structs.CompileDiagnostic{
+		{File: "main.cpp", Line: 5, Column: 9, Severity: "warning", Message: "unused variable 'x' [-Wunused-variable]"},
+		{File: "main.cpp", Line: 7, Column: 5, Severity: "error", Message: "'cot' was not declared in this scope"},
+		{File: "Main.java", Line: 3, Column: 0, Severity: "error", Message: "';' expected"},
+		{File: "src/main.rs", Line: 4, Column: 13, Severity: "error", Message: "cannot find value `y` in this scope"},
+		{File: "./prog.go", Line: 6, Column: 2, Severity: "error", Message: "undefined: fmtt"},
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expect %d diagnostics, got %d: %v", len(expected), len(diagnostics), diagnostics)
+		return
+	}
+	for i := range expected {
+		if diagnostics[i] != expected[i] {
+			t.Fatalf("diagnostic %d: expect %v, got %v", i, expected[i], diagnostics[i])
+			return
+		}
+	}
+}
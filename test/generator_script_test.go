@@ -0,0 +1,81 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"strings"
+	"testing"
+)
+
+// Test: generator script expansion
+func TestGeneratorScript(t *testing.T) {
+	script := `
+# comment
+gen 1 10 > 1
+gen 10 20 > $     # next
+gen {1..3} x{5..6} > $
+@seed 100
+gen 7 ${seed} > $
+gen 8 ${seed} > 20
+gen a#b "c #d" > $ # note
+gen "a b" 'it''s' x\ y > $
+`
+	items, err := utils.ParseGeneratorScriptFile(strings.NewReader(script))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expects := []struct {
+		Index   int
+		Command string
+	}{
+		{1, "gen 1 10"},
+		{2, "gen 10 20"},
+		{3, "gen 1 x5"},
+		{4, "gen 1 x6"},
+		{5, "gen 2 x5"},
+		{6, "gen 2 x6"},
+		{7, "gen 3 x5"},
+		{8, "gen 3 x6"},
+		{9, "gen 7 100"},
+		{20, "gen 8 101"},
+		{21, "gen a#b 'c #d'"},
+		{22, "gen 'a b' its 'x y'"},
+	}
+	if len(items) != len(expects) {
+		t.Fatalf("expect %d items, got %d", len(expects), len(items))
+		return
+	}
+	for i, item := range items {
+		if item.Index != expects[i].Index || item.Command() != expects[i].Command {
+			t.Fatalf("item %d: expect #%d '%s', got #%d '%s'", i, expects[i].Index, expects[i].Command, item.Index, item.Command())
+			return
+		}
+	}
+	// 带引号的参数作为一个参数传给generator
+	name, args, err := utils.ParseGeneratorScript(items[len(items)-1].Command())
+	if err != nil || name != "gen" || strings.Join(args, "|") != "a b|its|x y" {
+		t.Fatalf("unexpected generator arguments: %s %q, %v", name, args, err)
+		return
+	}
+	t.Log("OK")
+}
+
+// Test: generator script errors
+func TestGeneratorScriptError(t *testing.T) {
+	scripts := []string{
+		"gen 1 2",                  // no target
+		"gen 1 2 > 1\ngen 3 4 > 1", // duplicated index
+		"gen {1..2} > 3",           // range with explicit target
+		"gen {3..1} > $",           // empty range
+		"@unknown 1\ngen 1 2 > $",  // unknown directive
+		"gen \"1 2 > $",            // unterminated quote
+	}
+	for _, script := range scripts {
+		_, err := utils.ParseGeneratorScriptFile(strings.NewReader(script))
+		if err == nil {
+			t.Fatalf("script should be failed: %s", script)
+			return
+		}
+	}
+	t.Log("OK")
+}
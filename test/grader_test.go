@@ -0,0 +1,81 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// Test: submissions of function implementation problems are placed with the grader files
+func TestGraderCompiler(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "deer-grader")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(configDir)
+	writeProjectFiles(t, configDir, map[string]string{
+		"grader/grader.py":   "from solution import solve\nprint(solve(*map(int, input().split())))\n",
+		"grader/Grader.java": "public class Grader { public static void main(String[] args) { new Solution().solve(); } }\n",
+	})
+
+	session, err := executor.NewSession("")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer session.Clean()
+	session.ConfigDir = configDir
+	session.SessionDir = path.Join(configDir, "session")
+	_ = os.Mkdir(session.SessionDir, 0755)
+	session.JudgeConfig.Graders = map[string]structs.GraderOptions{
+		"python3": {Files: []string{"grader/grader.py"}},
+		"java":    {Files: []string{"grader/Grader.java"}},
+	}
+
+	session.CodeLangName = "python3"
+	compiler, err := session.GetCompiler("def solve(a, b):\n    return a + b\n")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	args := compiler.GetRunArgs()
+	if path.Base(args[len(args)-1]) != "grader.py" {
+		t.Fatalf("grader should be the entry: %v", args)
+		return
+	}
+	if _, err = os.Stat(path.Join(path.Dir(args[len(args)-1]), "solution.py")); err != nil {
+		t.Fatal("submission should be saved as solution.py")
+		return
+	}
+
+	session.CodeLangName = "java"
+	compiler, err = session.GetCompiler("public class Solution { int solve() { return 0; } }")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	args = compiler.GetRunArgs()
+	if args[len(args)-1] != "Grader" {
+		t.Fatalf("grader class should be the main class: %v", args)
+		return
+	}
+	if _, err = os.Stat(path.Join(args[len(args)-2], "Solution.java")); err != nil {
+		t.Fatal("submission should be saved as Solution.java")
+		return
+	}
+
+	// 没有设置grader的语言按普通的题目处理
+	session.CodeLangName = "g++"
+	compiler, err = session.GetCompiler("int main() { return 0; }")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(compiler.GetRunArgs()) != 1 {
+		t.Fatalf("unexpected run args: %v", compiler.GetRunArgs())
+	}
+}
@@ -0,0 +1,332 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+)
+
+// Test: match languages from the registry
+func TestLanguageRegistryMatch(t *testing.T) {
+	cases := map[string][2]string{
+		"g++":     {"auto", "a.cpp"},
+		"python3": {"py", ""},
+		"golang":  {"", "main.go"},
+		"nodejs":  {"auto", "index.js"},
+		"gcc":     {"gnu-c", "a.cpp"},
+	}
+	for name, item := range cases {
+		lang, err := provider.MatchLanguage(item[0], item[1])
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if lang.Name != name {
+			t.Fatalf("expect %s, got %s", name, lang.Name)
+			return
+		}
+	}
+	if _, err := provider.MatchLanguage("auto", "a.unknown"); err == nil {
+		t.Fatal("unknown extension should not be matched")
+	}
+}
+
+// Test: generic provider renders commands from the language definition
+func TestGenericCompileProvider(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-lang")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	java, err := provider.NewCompileProvider("java")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = java.Init("public class Solution { }", workDir)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if _, err = os.Stat(path.Join(workDir, "Solution.java")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	args := java.GetRunArgs()
+	if args[len(args)-1] != "Solution" || args[len(args)-2] != workDir {
+		t.Fatalf("unexpected run args: %v", args)
+		return
+	}
+
+	py, err := provider.NewCompileProvider("python3")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = py.Init("print(1)", workDir)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !py.IsReady() || !py.IsRealTime() {
+		t.Fatal("python3 should be a ready real-time language")
+		return
+	}
+	if !py.IsCompileError("  File \"a.py\", line 1\nSyntaxError: invalid syntax") {
+		t.Fatal("SyntaxError should be treated as compile error")
+	}
+}
+
+// Test: load additional languages from languages.json
+func TestPlaceLanguages(t *testing.T) {
+	fp, err := ioutil.TempFile("", "languages-*.json")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.Remove(fp.Name())
+	_, _ = fp.WriteString(`[{"name": "lua-test", "aliases": ["lt"], "extensions": [".luat"], "run_command": "lua {src}", "real_time": true, "jit_memory": 1024}]`)
+	_ = fp.Close()
+
+	if err = provider.PlaceLanguages(fp.Name()); err != nil {
+		t.Fatal(err)
+		return
+	}
+	lang, err := provider.MatchLanguage("auto", "main.luat")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !lang.HasName("lt") || provider.GetJITMemory("lua-test") != 1024 {
+		t.Fatal("language definition not loaded")
+		return
+	}
+	if strings.Join(lang.Extensions, ",") != ".luat" {
+		t.Fatalf("unexpected extensions: %v", lang.Extensions)
+	}
+}
+
+// Test: language variants inherit from their family
+func TestLanguageVariants(t *testing.T) {
+	lang, err := provider.MatchLanguage("c++17", "")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if lang.Name != "g++17" || provider.GetLanguageFamily(lang.Name) != "g++" {
+		t.Fatalf("unexpected language: %s", lang.Name)
+		return
+	}
+	if lang.RunCommand.String() != "{out}" || lang.SourceExtension() != ".cpp" {
+		t.Fatal("variant should inherit run command and source extension from its family")
+		return
+	}
+	if !lang.CompileCommand.HasPlaceholder("-std=c++17") {
+		t.Fatalf("unexpected compile command: %s", lang.CompileCommand)
+		return
+	}
+	pypy, err := provider.MatchLanguage("pypy3", "")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !pypy.RealTime || len(pypy.CompileErrorPatterns) == 0 || provider.GetJITMemory("pypy3") == provider.GetJITMemory("python3") {
+		t.Fatal("pypy3 should be a real-time language with its own jit memory")
+		return
+	}
+	if err = provider.RegisterLanguage(provider.LanguageDefinition{Name: "foo", Family: "not-exists"}); err == nil {
+		t.Fatal("variant of an unknown family should not be registered")
+	}
+}
+
+// Test: jit memory settings keyed by family apply to its variants
+func TestJITMemoryFamily(t *testing.T) {
+	defaults := constants.MemorySizeForJIT
+	defer func() { constants.MemorySizeForJIT = defaults }()
+	constants.MemorySizeForJIT = map[string]int{"g++": 4096, "clang++20": 8192}
+	if mem := provider.GetJITMemory("g++17"); mem != 4096 {
+		t.Fatalf("expect the family setting 4096, got %d", mem)
+	}
+	if mem := provider.GetJITMemory("clang++20"); mem != 8192 {
+		t.Fatalf("expect the language setting 8192, got %d", mem)
+	}
+	if mem := provider.GetJITMemory("pypy3"); mem != 262144 {
+		t.Fatalf("expect the registry setting 262144, got %d", mem)
+	}
+}
+
+// Test: parse legacy command strings and render argv templates
+func TestCommandTemplate(t *testing.T) {
+	args, err := utils.ParseShellWords(`g++ "my code.cpp" -o 'a b' -DNAME=\"x\" -O2`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expect := []string{"g++", "my code.cpp", "-o", "a b", `-DNAME="x"`, "-O2"}
+	if strings.Join(args, "|") != strings.Join(expect, "|") {
+		t.Fatalf("unexpected args: %q", args)
+		return
+	}
+	if _, err = utils.ParseShellWords(`gcc "a.c`); err == nil {
+		t.Fatal("unterminated quote should be an error")
+		return
+	}
+
+	tpl := provider.CommandTemplate{"g++", "{src}", "-o", "{out}", "{include}", "-DONLINE_JUDGE"}
+	args = tpl.Render(provider.CommandVariables{
+		Source:     "/tmp/work dir/a.cpp",
+		Program:    "/tmp/work dir/a",
+		IncludeDir: []string{"/opt/lib"},
+	})
+	expect = []string{"g++", "/tmp/work dir/a.cpp", "-o", "/tmp/work dir/a", "-I", "/opt/lib", "-DONLINE_JUDGE"}
+	if strings.Join(args, "|") != strings.Join(expect, "|") {
+		t.Fatalf("unexpected args: %q", args)
+		return
+	}
+	if len(tpl.Render(provider.CommandVariables{})) != len(tpl)-1 {
+		t.Fatal("empty {include} should be dropped")
+	}
+
+	// 配置文件中的字符串写法
+	lang := provider.LanguageDefinition{}
+	err = json.Unmarshal([]byte(`{"compile_command": "gcc {src} -o {out} -DPATH='/a b'", "run_command": ["{out}"]}`), &lang)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if lang.CompileCommand[len(lang.CompileCommand)-1] != "-DPATH=/a b" || lang.RunCommand.String() != "{out}" {
+		t.Fatalf("unexpected commands: %s / %s", lang.CompileCommand, lang.RunCommand)
+	}
+}
+
+// Test: C#, Kotlin, Pascal, Haskell, Lua, Perl and Bash are registered
+func TestMoreLanguagesRegistered(t *testing.T) {
+	files := map[string]string{
+		"csharp":  "a.cs",
+		"kotlin":  "a.kt",
+		"pascal":  "a.pas",
+		"haskell": "a.hs",
+		"lua":     "a.lua",
+		"perl":    "a.pl",
+		"bash":    "a.sh",
+	}
+	for name, file := range files {
+		lang, err := provider.MatchLanguage("auto", file)
+		if err != nil || lang.Name != name {
+			t.Fatalf("%s should be matched as %s: %v", file, name, err)
+			return
+		}
+	}
+
+	csharp, err := provider.NewCompileProvider("c#")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !csharp.IsExitCodeRuntimeError(1) || csharp.IsExitCodeRuntimeError(0) {
+		t.Fatal("non-zero exit code of c# should be a runtime error")
+		return
+	}
+	lua, err := provider.NewCompileProvider("lua")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !lua.IsRealTime() || !lua.IsCompileError("lua: a.lua:2: ')' expected (to close '(' at line 1) near 'print'") {
+		t.Fatal("lua syntax error should be a compile error")
+		return
+	}
+	if lua.IsCompileError("lua: a.lua:1: attempt to perform arithmetic on a nil value") || lua.IsExitCodeRuntimeError(1) {
+		t.Fatal("lua runtime error should not be a compile error")
+	}
+}
+
+// Test: detect languages from source content
+func TestDetectLanguage(t *testing.T) {
+	err := initWorkRoot()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	files := map[string]string{
+		"ac.c": "gcc", "ac.cpp": "g++", "ac.java": "java", "ac.go": "golang", "ac.rs": "rust",
+		"ac.php": "php", "ac.rb": "ruby", "ac.js": "nodejs", "ac_py2.py": "python2", "ac_py3.py": "python3",
+		"ac.cs": "csharp", "ac.kt": "kotlin", "ac.pas": "pascal", "ac.hs": "haskell", "ac.lua": "lua",
+		"ac.pl": "perl", "ac.sh": "bash",
+	}
+	for file, name := range files {
+		code, err := ioutil.ReadFile(path.Join("./data/codes/APlusB", file))
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		// 网页提交的代码通常没有正确的扩展名
+		lang, guesses, err := provider.MatchLanguageWithCode("auto", "code.txt", string(code))
+		if err != nil || lang.Name != name {
+			t.Fatalf("%s should be detected as %s: %v %v", file, name, guesses, err)
+			return
+		}
+	}
+
+	// .py对应python2和python3，按代码内容选择
+	lang, _, err := provider.MatchLanguageWithCode("auto", "a.py", "#!/usr/bin/env python2\nprint 'hello'\n")
+	if err != nil || lang.Name != "python2" {
+		t.Fatalf("expect python2, got %v %v", lang, err)
+		return
+	}
+	lang, guesses, err := provider.MatchLanguageWithCode("auto", "a.cpp", "")
+	if err != nil || lang.Name != "g++" || guesses != nil {
+		t.Fatal("known extension should be used directly")
+		return
+	}
+	if _, _, err = provider.MatchLanguageWithCode("auto", "code.txt", "hello world"); err == nil {
+		t.Fatal("unknown content should not be matched")
+		return
+	}
+
+	// 网页提交中常见的写法
+	pasted := map[string]string{
+		"a, b = map(int, input().split())\nprint(a + b)\n":                                                                 "python3",
+		"#include <cstdio>\nint main() {\n    int a, b;\n    scanf(\"%d%d\", &a, &b);\n    printf(\"%d\\n\", a + b);\n}\n": "g++",
+	}
+	for code, name := range pasted {
+		lang, guesses, err := provider.MatchLanguageWithCode("auto", "code.txt", code)
+		if err != nil || lang.Name != name {
+			t.Fatalf("%q should be detected as %s: %v %v", code, name, guesses, err)
+			return
+		}
+	}
+}
+
+// Test: syntax probes tell python2 from python3 when features are not enough
+func TestProbeLanguageSyntax(t *testing.T) {
+	err := initWorkRoot()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	// python2的解释器为python，需要是python 2才能区分
+	version, err := exec.Command("python", "-c", "import sys; print(sys.version_info[0])").Output()
+	if err != nil || strings.TrimSpace(string(version)) != "2" {
+		t.Skip("python 2 not found")
+		return
+	}
+	if _, err = exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not found")
+		return
+	}
+	code := "import sys\ntry:\n    x = int(sys.stdin.readline())\nexcept ValueError, e:\n    x = 0\nsys.stdout.write(str(x))\n"
+	lang, guesses, err := provider.MatchLanguageWithCode("auto", "code.txt", code)
+	if err != nil || lang.Name != "python2" {
+		t.Fatalf("expect python2, got %v %v", guesses, err)
+	}
+}
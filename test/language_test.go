@@ -3,6 +3,8 @@ package test
 import (
 	"flag"
 	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"os/exec"
 	"testing"
 )
 
@@ -246,3 +248,53 @@ func TestAPlusBProblemPHP(t *testing.T) {
 //	}
 //	t.Log("OK")
 //}
+
+// Test: C#, Kotlin, Pascal, Haskell, Lua, Perl and Bash (skip the languages which toolchain is not installed)
+func TestAPlusBProblemMoreLanguages(t *testing.T) {
+	if flag.Arg(0) != "all-language" {
+		t.Log("More languages: Skip")
+		return
+	}
+	err := initWorkRoot()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	cases := []struct {
+		Language string
+		CodeFile string
+		Expect   int
+	}{
+		{"csharp", "./data/codes/APlusB/ac.cs", constants.JudgeFlagAC},
+		{"csharp", "./data/codes/APlusB/re.cs", constants.JudgeFlagRE},
+		{"kotlin", "./data/codes/APlusB/ac.kt", constants.JudgeFlagAC},
+		{"pascal", "./data/codes/APlusB/ac.pas", constants.JudgeFlagAC},
+		{"haskell", "./data/codes/APlusB/ac.hs", constants.JudgeFlagAC},
+		{"lua", "./data/codes/APlusB/ac.lua", constants.JudgeFlagAC},
+		{"lua", "./data/codes/APlusB/ce.lua", constants.JudgeFlagCE},
+		{"perl", "./data/codes/APlusB/ac.pl", constants.JudgeFlagAC},
+		{"bash", "./data/codes/APlusB/ac.sh", constants.JudgeFlagAC},
+	}
+	for _, item := range cases {
+		lang, ok := provider.GetLanguage(item.Language)
+		if !ok {
+			t.Fatalf("language %s not registered", item.Language)
+			return
+		}
+		if _, err = exec.LookPath(lang.VersionCommand[0]); err != nil {
+			t.Logf("%s: Skip (%s not installed)", item.Language, lang.VersionCommand[0])
+			continue
+		}
+		result, err := runAPlusB(item.CodeFile, item.Language)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		err = analysisResult("test "+item.CodeFile, result, item.Expect)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	t.Log("OK")
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// Test: forbidden-construct checks before compiling
+func TestLintCode(t *testing.T) {
+	cppCode := "#include <cstdio>\n" +
+		"#include \"algorithm\"\n" +
+		"// goto is fine in comments, system(\"ls\") too\n" +
+		"int main() {\n" +
+		"    const char *s = \"goto /* not a comment */\";\n" +
+		"    /* system(\"rm\")\n" +
+		"       goto end; */\n" +
+		"    goto end;\n" +
+		"end:\n" +
+		"    return 0;\n" +
+		"}\n"
+	pyCode := "import sys, os as o  # import subprocess\n" +
+		"s = '''\n" +
+		"import socket\n" +
+		"'''\n" +
+		"from collections import deque\n" +
+		"print(eval('1+1'))\n"
+	goCode := "package main\n\nimport (\n\t\"fmt\"\n\t// \"os\"\n\t\"os/exec\"\n)\n"
+	javaCode := "import java.util.Scanner;\nimport java.io.*;\n\npublic class Main {\n    // Runtime.getRuntime()\n}\n"
+
+	cases := []struct {
+		Code     string
+		Language string
+		Options  structs.LintOptions
+		Line     int
+	}{
+		{cppCode, "g++", structs.LintOptions{Keywords: []string{"goto"}}, 8},
+		{cppCode, "g++17", structs.LintOptions{Keywords: []string{"system"}}, 0},
+		{cppCode, "g++", structs.LintOptions{Patterns: []string{`/\*`, `return\s+0`}}, 10},
+		{cppCode, "g++", structs.LintOptions{ForbiddenImports: []string{"algorithm"}}, 2},
+		{cppCode, "g++", structs.LintOptions{AllowedImports: []string{"cstdio", "algorithm"}}, 0},
+		{cppCode, "gcc", structs.LintOptions{AllowedImports: []string{"stdio.h"}}, 1},
+		{pyCode, "python3", structs.LintOptions{ForbiddenImports: []string{"os"}}, 1},
+		{pyCode, "python3", structs.LintOptions{ForbiddenImports: []string{"subprocess", "socket"}}, 0},
+		{pyCode, "python3", structs.LintOptions{AllowedImports: []string{"sys", "os"}}, 5},
+		{pyCode, "python3", structs.LintOptions{Keywords: []string{"eval("}}, 6},
+		{pyCode, "python3", structs.LintOptions{Keywords: []string{"socket"}}, 0},
+		{goCode, "golang", structs.LintOptions{ForbiddenImports: []string{"os"}}, 6},
+		{goCode, "golang", structs.LintOptions{AllowedImports: []string{"fmt", "os/exec"}}, 0},
+		{javaCode, "java", structs.LintOptions{AllowedImports: []string{"java.util.*"}}, 2},
+		{javaCode, "java", structs.LintOptions{Keywords: []string{"Runtime"}}, 0},
+	}
+	for i, item := range cases {
+		violation, err := executor.LintCode(item.Code, item.Language, item.Options)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		line := 0
+		if violation != nil {
+			line = violation.Line
+		}
+		if line != item.Line {
+			t.Fatalf("case %d: expect violation at line %d, got %v", i, item.Line, violation)
+			return
+		}
+	}
+
+	if _, err := executor.LintCode(cppCode, "g++", structs.LintOptions{Patterns: []string{"("}}); err == nil {
+		t.Fatal("invalid pattern should be reported")
+	}
+}
+
+// Test: every file of a multi-file submission is checked
+func TestLintProject(t *testing.T) {
+	projectDir, err := ioutil.TempDir("", "deer-lint-")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(projectDir)
+	_ = os.MkdirAll(path.Join(projectDir, "util"), 0755)
+	_ = ioutil.WriteFile(path.Join(projectDir, "main.cpp"), []byte("#include \"util/helper.h\"\nint main() { return 0; }\n"), 0644)
+	_ = ioutil.WriteFile(path.Join(projectDir, "util", "helper.h"), []byte("#pragma once\n#include <algorithm>\n"), 0644)
+	_ = ioutil.WriteFile(path.Join(projectDir, "README"), []byte("build with make\n"), 0644)
+
+	options := structs.LintOptions{ForbiddenImports: []string{"algorithm"}}
+	violation, err := executor.LintProject(projectDir, "g++", options)
+	if err != nil || violation == nil || violation.File != "util/helper.h" || violation.Line != 2 {
+		t.Fatalf("expect violation at util/helper.h:2, got %v, %v", violation, err)
+		return
+	}
+	violation, err = executor.LintProject(projectDir, "g++", structs.LintOptions{Keywords: []string{"system"}})
+	if err != nil || violation != nil {
+		t.Fatalf("expect no violation, got %v, %v", violation, err)
+	}
+}
+
+// Test: only the answers of a code-fill submission are checked
+func TestLintCodeFillAnswers(t *testing.T) {
+	answers := map[string]string{
+		"read":  "    scanf(\"%d\", &a);",
+		"print": "    printf(\"%d\\n\", a);\n    system(\"ls\");",
+	}
+	options := structs.LintOptions{Keywords: []string{"system"}}
+	violation, err := executor.LintCodeFillAnswers(answers, "gcc", options)
+	if err != nil || violation == nil || violation.File != "blank (print)" || violation.Line != 2 {
+		t.Fatalf("expect violation at blank (print) line 2, got %v, %v", violation, err)
+		return
+	}
+	// 代码模板中的内容(如头文件)不检查
+	violation, err = executor.LintCodeFillAnswers(answers, "gcc", structs.LintOptions{AllowedImports: []string{"none"}})
+	if err != nil || violation != nil {
+		t.Fatalf("expect no violation, got %v, %v", violation, err)
+	}
+}
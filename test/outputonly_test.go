@@ -0,0 +1,65 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// Test: output-only submissions are compared with the answers directly
+func TestOutputOnlyJudge(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "deer-output-only")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(configDir)
+	writeProjectFiles(t, configDir, map[string]string{
+		"cases/1.in":              "1 2\n",
+		"cases/1.out":             "3\n",
+		"cases/2.in":              "3 4\n",
+		"cases/2.out":             "7\n",
+		"cases/3.in":              "5 6\n",
+		"cases/3.out":             "11\n",
+		"submission/answer/1.out": "3\n",
+		"submission/answer/2.out": "8\n",
+	})
+
+	session, err := executor.NewSession("")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	session.ConfigDir = configDir
+	session.JudgeConfig.ConfigDir = configDir
+	session.SessionDir = path.Join(configDir, "session")
+	_ = os.Mkdir(session.SessionDir, 0755)
+	session.CodeFile = path.Join(configDir, "submission")
+	session.JudgeConfig.Problem.ProblemType = constants.ProblemTypeOutputOnly
+	session.JudgeConfig.TestCases = []structs.TestCase{
+		{Handle: "1", Input: "cases/1.in", Output: "cases/1.out"},
+		{Handle: "2", Input: "cases/2.in", Output: "cases/2.out"},
+		{Handle: "3", Input: "cases/3.in", Output: "cases/3.out"},
+	}
+	session.RunAllCases = true
+
+	result := session.RunJudge()
+	if len(result.TestCases) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+		return
+	}
+	expect := []int{constants.JudgeFlagAC, constants.JudgeFlagWA, constants.JudgeFlagWA}
+	for i, tc := range result.TestCases {
+		if tc.JudgeResult != expect[i] {
+			t.Fatalf("test case %s: expect %d, got %d (%s)", tc.Handle, expect[i], tc.JudgeResult, tc.SeInfo)
+			return
+		}
+	}
+	if result.JudgeResult != constants.JudgeFlagWA {
+		t.Fatalf("unexpected final result: %d", result.JudgeResult)
+	}
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// 写入测试用的文件
+func writeProjectFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		fpath := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fpath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Test: archives with path traversal entries should be rejected
+func TestExtractArchiveZipSlip(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-project")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	archive := path.Join(workDir, "evil.zip")
+	fp, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	zw := zip.NewWriter(fp)
+	w, _ := zw.Create("../evil.txt")
+	_, _ = w.Write([]byte("evil"))
+	_ = zw.Close()
+	_ = fp.Close()
+
+	destDir := path.Join(workDir, "dest")
+	_ = os.Mkdir(destDir, 0755)
+	if err = utils.ExtractArchive(archive, destDir); err == nil {
+		t.Fatal("zip-slip entry should be rejected")
+		return
+	}
+	if _, err = os.Stat(path.Join(workDir, "evil.txt")); err == nil {
+		t.Fatal("file outside the destination should not be written")
+	}
+}
+
+// Test: unpack a java package project and detect its entry
+func TestJavaProjectSubmission(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-project")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	archive := path.Join(workDir, "code.tar.gz")
+	fp, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	gw := gzip.NewWriter(fp)
+	tw := tar.NewWriter(gw)
+	files := map[string]string{
+		"code/com/example/Main.java": "package com.example;\npublic class Main { public static void main(String[] args) { Util.hello(); } }\n",
+		"code/com/example/Util.java": "package com.example;\npublic class Util { static void hello() {} }\n",
+	}
+	for name, content := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	_ = fp.Close()
+
+	projectDir := path.Join(workDir, "project")
+	_ = os.Mkdir(projectDir, 0755)
+	if err = utils.ExtractArchive(archive, projectDir); err != nil {
+		t.Fatal(err)
+		return
+	}
+	projectDir = utils.UnwrapSingleDirectory(projectDir)
+	compiler, err := provider.NewProjectCompileProvider("auto", projectDir, structs.ProjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err = compiler.Init("", workDir); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if compiler.GetName() != "java" || path.Base(compiler.GetEntry()) != "Main.java" {
+		t.Fatalf("unexpected project: %s", compiler.String())
+		return
+	}
+	args := compiler.GetRunArgs()
+	if args[len(args)-1] != "com.example.Main" || args[len(args)-2] != projectDir {
+		t.Fatalf("unexpected run args: %v", args)
+	}
+}
+
+// Test: detect the entry of a C project, and report ambiguous entries
+func TestCProjectSubmission(t *testing.T) {
+	projectDir, err := ioutil.TempDir("", "deer-project")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(projectDir)
+
+	writeProjectFiles(t, projectDir, map[string]string{
+		"solve.h":      "int solve(int a, int b);\n",
+		"solve.c":      "#include \"solve.h\"\nint solve(int a, int b) { return a + b; }\n",
+		"src/domain.c": "int domain(void) { return 0; }\n",
+		"app.c":        "#include <stdio.h>\n#include \"solve.h\"\nint main() { return solve(1, 2) - 3; }\n",
+	})
+	compiler, err := provider.NewProjectCompileProvider("auto", projectDir, structs.ProjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err = compiler.Init("", projectDir); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if compiler.GetName() != "gcc" || path.Base(compiler.GetEntry()) != "app.c" {
+		t.Fatalf("unexpected project: %s", compiler.String())
+		return
+	}
+
+	writeProjectFiles(t, projectDir, map[string]string{"other.c": "int main() { return 0; }\n"})
+	compiler, _ = provider.NewProjectCompileProvider("c", projectDir, structs.ProjectOptions{})
+	if err = compiler.Init("", projectDir); err == nil {
+		t.Fatal("multiple entries should be an error")
+		return
+	}
+	compiler, _ = provider.NewProjectCompileProvider("c", projectDir, structs.ProjectOptions{Entry: "other.c"})
+	if err = compiler.Init("", projectDir); err != nil || path.Base(compiler.GetEntry()) != "other.c" {
+		t.Fatalf("entry option should be used: %v", err)
+	}
+}
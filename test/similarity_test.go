@@ -0,0 +1,130 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/persistence"
+	"github.com/LanceLRQ/deer-executor/v2/common/persistence/result"
+	"github.com/LanceLRQ/deer-executor/v2/common/similarity"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+const similarityOrigin = `#include <cstdio>
+int a[100005];
+int main() {
+    int n, sum = 0;
+    scanf("%d", &n);
+    for (int i = 0; i < n; i++) {
+        scanf("%d", &a[i]);
+        if (a[i] % 2 == 0) sum += a[i];
+    }
+    printf("%d\n", sum);
+    return 0;
+}
+`
+
+// 修改了变量名、注释和格式
+const similarityRenamed = `#include <cstdio>
+// my own solution
+int arr[200005];
+int main()
+{
+    int count, total = 0;  /* total of even numbers */
+    scanf("%d", &count);
+    for (int k = 0; k < count; k++)
+    {
+        scanf("%d", &arr[k]);
+        if (arr[k] % 2 == 0) total += arr[k];
+    }
+    printf("%d\n", total);
+    return 0;
+}
+`
+
+const similarityOther = `#include <iostream>
+#include <string>
+using namespace std;
+struct Node { string name; Node *next; };
+int main() {
+    string s;
+    Node *head = nullptr;
+    while (cin >> s) head = new Node{s, head};
+    for (Node *p = head; p != nullptr; p = p->next) cout << p->name << endl;
+}
+`
+
+// Test: normalized winnowing fingerprints
+func TestSimilarityDetect(t *testing.T) {
+	options := similarity.Options{Threshold: 0.3}
+	submissions := []*similarity.Submission{
+		similarity.NewSubmission("origin.cpp", "g++", similarityOrigin, options),
+		similarity.NewSubmission("renamed.cpp", "g++17", similarityRenamed, options),
+		similarity.NewSubmission("other.cpp", "g++", similarityOther, options),
+		similarity.NewSubmission("origin.c", "gcc", similarityOrigin, options),
+	}
+	report := similarity.Detect(submissions, nil, options)
+	if len(report.Pairs) != 1 {
+		t.Fatalf("expect 1 similar pair, got %d: %+v", len(report.Pairs), report.Pairs)
+		return
+	}
+	pair := report.Pairs[0]
+	if pair.A != "origin.cpp" || pair.B != "renamed.cpp" || pair.Similarity < 0.9 {
+		t.Fatalf("unexpected pair: %+v", pair)
+		return
+	}
+	if len(pair.Regions) == 0 || pair.Regions[0].StartLineB < 1 {
+		t.Fatalf("matched regions not found: %+v", pair.Regions)
+		return
+	}
+
+	// 模板代码中的指纹不计入相似度
+	bases := []*similarity.Submission{similarity.NewSubmission("base.cpp", "g++", similarityOrigin, options)}
+	report = similarity.Detect(submissions, bases, options)
+	for _, pair := range report.Pairs {
+		if pair.A == "origin.cpp" && pair.B == "renamed.cpp" {
+			t.Fatalf("base code should be ignored: %+v", pair)
+			return
+		}
+	}
+}
+
+// Test: source code saved in judge result package
+func TestJudgeResultSources(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-result-")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+	codeFile := path.Join(workDir, "main.cpp")
+	if err = ioutil.WriteFile(codeFile, []byte(similarityOrigin), 0644); err != nil {
+		t.Fatal(err)
+		return
+	}
+	options := &persistence.JudgeResultPersisOptions{
+		CompressorType: 1,
+		SessionDir:     workDir,
+		SaveSource:     true,
+		CodeFile:       codeFile,
+	}
+	options.OutFile = path.Join(workDir, "result.bin")
+	err = result.PersistentJudgeResult(&structs.JudgeResult{SessionID: "test"}, options)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !result.IsJudgeResultFile(options.OutFile) || result.IsJudgeResultFile(codeFile) {
+		t.Fatal("judge result file detection failed")
+		return
+	}
+	judgeResult, sources, err := result.ReadJudgeResultSources(options.OutFile)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if judgeResult.SessionID != "test" || string(sources["main.cpp"]) != similarityOrigin {
+		t.Fatalf("unexpected result: %+v, sources: %v", judgeResult, sources)
+	}
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Test: compare sql query result sets
+func TestCompareSQLResult(t *testing.T) {
+	expected := []byte("name,score\nalice,90.5\nbob,80\n\"c, d\",70\n")
+	cases := []struct {
+		Actual  string
+		Options structs.SQLOptions
+		Same    bool
+	}{
+		{"name,score\nalice,90.5\nbob,80\n\"c, d\",70\n", structs.SQLOptions{Ordered: true, CheckColumnNames: true}, true},
+		{"name,score\nbob,80\n\"c, d\",70\nalice,90.5\n", structs.SQLOptions{}, true},
+		{"name,score\nbob,80\n\"c, d\",70\nalice,90.5\n", structs.SQLOptions{Ordered: true}, false},
+		{"n,s\nalice,90.5\nbob,80\n\"c, d\",70\n", structs.SQLOptions{}, true},
+		{"n,s\nalice,90.5\nbob,80\n\"c, d\",70\n", structs.SQLOptions{CheckColumnNames: true}, false},
+		{"name,score\nalice,90.5000001\n\"c, d\",70\nbob,80.0\n", structs.SQLOptions{FloatTolerance: 1e-6}, true},
+		{"name,score\nalice,90.5000001\n\"c, d\",70\nbob,80\n", structs.SQLOptions{}, false},
+		{"name,score\nalice,90.5\nbob,80\n", structs.SQLOptions{}, false},
+		{"name\nalice\nbob\nc, d\n", structs.SQLOptions{}, false},
+		{"", structs.SQLOptions{}, false},
+	}
+	for i, item := range cases {
+		same, reason, err := executor.CompareSQLResult(expected, []byte(item.Actual), item.Options)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if same != item.Same {
+			t.Fatalf("case %d: expect %v, got %v (%s)", i, item.Same, same, reason)
+			return
+		}
+	}
+	// 空的查询结果 (sqlite3不会输出表头)
+	if same, _, _ := executor.CompareSQLResult([]byte(""), []byte("\n"), structs.SQLOptions{CheckColumnNames: true}); !same {
+		t.Fatal("empty result sets should be the same")
+	}
+}
+
+// Test: sqlite dot-commands are refused in submissions and by the safe mode
+func TestSQLSubmissionSafety(t *testing.T) {
+	for _, query := range []string{".shell touch /tmp/pwned\nSELECT 1;", "SELECT 1;\n  .system id", ".output /tmp/x"} {
+		if err := executor.CheckSQLSubmission([]byte(query)); err == nil {
+			t.Fatalf("dot-command should be refused: %q", query)
+			return
+		}
+	}
+	if err := executor.CheckSQLSubmission([]byte("SELECT name\nFROM t\nWHERE x = '.shell';")); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not found")
+		return
+	}
+	// 安全模式下即使绕过了检查，.shell和.system也不能执行
+	for _, query := range []string{".shell echo pwned", ".system echo pwned", "SELECT writefile('pwned', 'x');"} {
+		cmd := exec.Command("sqlite3", "-safe", "-batch", "-bail", ":memory:")
+		cmd.Stdin = strings.NewReader(query)
+		output, err := cmd.CombinedOutput()
+		if err == nil || strings.Contains(string(output), "pwned\n") {
+			t.Fatalf("%q should be refused in safe mode: %s", query, output)
+			return
+		}
+	}
+}
+
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"strings"
+	"testing"
+)
+
+// Test: compare output with the answer while running
+func TestStreamComparator(t *testing.T) {
+	answer := "1 2 3\n4 5 6\n"
+	cases := []struct {
+		Chunks  []string
+		Verdict int
+		Aborted int // index of the chunk which causes abort, -1 means not aborted
+	}{
+		{[]string{"1 2 3\n", "4 5 6\n"}, 0, -1},
+		{[]string{"1 2", " 3\r\n4  5\t6"}, 0, -1}, // PE is decided by DiffText after exit
+		{[]string{"1 2 3\n4", " 5 7\n", "8 9\n"}, constants.JudgeFlagWA, 1},
+		{[]string{"1 2 3\n4 5 6\n", "7\n"}, constants.JudgeFlagWA, 1},
+		{[]string{"1 2 3\n4 5 6\n", strings.Repeat(" ", 20)}, constants.JudgeFlagOLE, 1},
+		// 和DiffText相同，大小的检查先于内容的比较
+		{[]string{"1 2 3\n4 5 6\n" + strings.Repeat("x", 24)}, constants.JudgeFlagOLE, 0},
+	}
+	for i, item := range cases {
+		comparator := executor.NewStreamComparator(strings.NewReader(answer), int64(len(answer)), 1024)
+		aborted := -1
+		for k, chunk := range item.Chunks {
+			if _, err := comparator.Write([]byte(chunk)); err != nil {
+				aborted = k
+				break
+			}
+		}
+		if comparator.Verdict != item.Verdict || aborted != item.Aborted {
+			t.Fatalf("case %d: expect verdict %d at chunk %d, got %d at chunk %d (%s)",
+				i, item.Verdict, item.Aborted, comparator.Verdict, aborted, comparator.DiffLog())
+			return
+		}
+	}
+
+	// 超过输出大小限制
+	comparator := executor.NewStreamComparator(strings.NewReader(answer), int64(len(answer)), 8)
+	if _, err := comparator.Write([]byte("1 2 3\n4 5 6\n")); err == nil || comparator.Verdict != constants.JudgeFlagOLE {
+		t.Fatalf("expect OLE, got %d", comparator.Verdict)
+	}
+}
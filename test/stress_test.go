@@ -0,0 +1,23 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"strings"
+	"testing"
+)
+
+// Test: shrink a counterexample by delta-debugging
+func TestShrinkByDeltaDebugging(t *testing.T) {
+	units := strings.Fields("1 7 3 9 4 13 2 8")
+	// 只要同时包含"9"和"13"就能复现错误
+	shrunk, err := utils.ShrinkByDeltaDebugging(units, func(items []string) (bool, error) {
+		return utils.Contains(items, "9") && utils.Contains(items, "13"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if strings.Join(shrunk, " ") != "9 13" {
+		t.Fatalf("expect '9 13', got '%s'", strings.Join(shrunk, " "))
+	}
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"testing"
+)
+
+// Test: parse testlib validator's overview log
+func TestTestlibOverviewLog(t *testing.T) {
+	content := "\"n\": min-value-hit max-value-hit\n\"a[i]\": max-value-hit\n\"m\":\nfeature \"tree\": hit\nfeature \"loop\":\n"
+	bounds, features := utils.ParseTestlibOverviewLog(content)
+	if len(bounds) != 3 || len(features) != 2 {
+		t.Fatalf("expect 3 bounds and 2 features, got %d and %d", len(bounds), len(features))
+		return
+	}
+	if !bounds["n"].MinHit || !bounds["n"].MaxHit {
+		t.Fatal("variable 'n' should hit both min and max value")
+		return
+	}
+	if bounds["a[i]"].MinHit || !bounds["a[i]"].MaxHit {
+		t.Fatal("variable 'a[i]' should only hit max value")
+		return
+	}
+	if bounds["m"].MinHit || bounds["m"].MaxHit {
+		t.Fatal("variable 'm' should not hit any bounds")
+		return
+	}
+	if !features["tree"] || features["loop"] {
+		t.Fatal("feature 'tree' should be hit and 'loop' should not")
+		return
+	}
+	t.Log("OK")
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// Test: timeouts of the problem config take precedence over the system config
+func TestTimeoutOptions(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "deer-timeouts-")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	defaults := executor.SystemTimeouts
+	defer func() { executor.SystemTimeouts = defaults }()
+
+	configFile := path.Join(workDir, "timeouts.json")
+	_ = ioutil.WriteFile(configFile, []byte(`{"compile": 8000, "run": 20000, "total": 60000}`), 0644)
+	if err = executor.PlaceSystemTimeouts(configFile); err != nil {
+		t.Fatal(err)
+		return
+	}
+	config := structs.JudgeConfiguration{}
+	config.Timeouts.Run = 3000
+	config.Timeouts.Validator = 1000
+	timeouts := executor.GetTimeouts(&config)
+	expected := structs.TimeoutOptions{
+		Compile:   8000,
+		Run:       3000,
+		Generator: defaults.Generator, // not set in timeouts.json
+		Validator: 1000,
+		Total:     60000,
+	}
+	if timeouts != expected {
+		t.Fatalf("expect %+v, got %+v", expected, timeouts)
+	}
+
+	// 系统配置文件不存在时保留默认值
+	executor.SystemTimeouts = defaults
+	if err = executor.PlaceSystemTimeouts(path.Join(workDir, "not_exists.json")); err != nil || executor.SystemTimeouts != defaults {
+		t.Fatalf("expect defaults, got %+v, %v", executor.SystemTimeouts, err)
+	}
+}
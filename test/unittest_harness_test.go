@@ -0,0 +1,93 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/LanceLRQ/deer-executor/v2/common/structs"
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"syscall"
+	"testing"
+)
+
+// unittest的运行摘要写到stderr，有测试失败时以非0的退出代码结束，报告(json)写到stdout
+const unitTestHarness = `import json, sys, unittest
+
+class SolutionTest(unittest.TestCase):
+    def test_add(self):
+        self.assertEqual(1 + 1, 2)
+
+    def test_sub(self):
+        self.assertEqual(1 - 1, 1)
+
+result = unittest.TextTestRunner(stream=sys.stderr, verbosity=2).run(
+    unittest.defaultTestLoader.loadTestsFromTestCase(SolutionTest))
+failed = set(test._testMethodName for test, _ in result.failures + result.errors)
+tests = [{"name": name, "status": "failed" if name in failed else "passed"} for name in ("test_add", "test_sub")]
+print(json.dumps({"tests": tests}))
+sys.exit(0 if result.wasSuccessful() else 1)
+`
+
+// Test: a harness writing to stderr and exiting non-zero is judged by its report
+func TestUnitTestHarnessReport(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not found")
+		return
+	}
+	workDir, err := ioutil.TempDir("", "deer-unittest-")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+	harnessFile := path.Join(workDir, "harness.py")
+	_ = ioutil.WriteFile(harnessFile, []byte(unitTestHarness), 0644)
+
+	stdout, _ := os.Create(path.Join(workDir, "unit_test_program.out"))
+	stderr, _ := os.Create(path.Join(workDir, "unit_test_program.err"))
+	harnessCmd := exec.Command("python3", harnessFile)
+	harnessCmd.Stdout, harnessCmd.Stderr = stdout, stderr
+	_ = harnessCmd.Run()
+	_ = stdout.Close()
+	_ = stderr.Close()
+	if harnessCmd.ProcessState == nil || harnessCmd.ProcessState.ExitCode() != 1 {
+		t.Fatalf("harness should exit with code 1: %v", harnessCmd.ProcessState)
+		return
+	}
+	pinfo := &executor.ProcessInfo{
+		Status: harnessCmd.ProcessState.Sys().(syscall.WaitStatus),
+		Rusage: harnessCmd.ProcessState.SysUsage().(*syscall.Rusage),
+	}
+
+	// python为实时运行的语言，csharp的非0退出代码视为运行错误，两者都不影响测试程序的结果
+	for _, lang := range []string{"python3", "csharp"} {
+		session, err := executor.NewSession("")
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		session.SessionDir = workDir
+		session.JudgeConfig.TimeLimit = 10000
+		session.JudgeConfig.MemoryLimit = 1024 * 1024
+		session.JudgeConfig.UnitTest.ReportFormat = "json"
+		if session.Compiler, err = provider.NewCompileProvider(lang); err != nil {
+			t.Fatal(err)
+			return
+		}
+		harness := structs.TestCaseResult{
+			Handle:       "unit_test",
+			ProgramOut:   "unit_test_program.out",
+			ProgramError: "unit_test_program.err",
+		}
+		session.AnalysisUnitTestHarness(&harness, pinfo)
+		result := structs.JudgeResult{}
+		session.CollectUnitTestReport(&result, &harness)
+		if result.JudgeResult != constants.JudgeFlagWA || result.Score != 1 || result.FullScore != 2 || len(result.TestCases) != 2 {
+			t.Fatalf("%s: expect WA with score 1/2, got %d, %d/%d: %s%s", lang, result.JudgeResult, result.Score, result.FullScore, result.ReInfo, result.SeInfo)
+			return
+		}
+	}
+}
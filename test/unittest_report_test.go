@@ -0,0 +1,57 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/executor"
+	"testing"
+)
+
+// Test: parse junit xml and json unit test reports
+func TestParseUnitTestReport(t *testing.T) {
+	junit := `<?xml version="1.0" encoding="utf-8"?>
+<testsuites>
+  <testsuite name="pytest" tests="3">
+    <testcase classname="test_solution" name="test_add" time="0.001"/>
+    <testcase classname="test_solution" name="test_neg" time="0.002">
+      <failure message="assert -1 == 1">def test_neg(): ...</failure>
+    </testcase>
+    <testcase classname="test_solution" name="test_big" time="1,000.5"><error message="MemoryError"/></testcase>
+  </testsuite>
+</testsuites>`
+	items, err := executor.ParseUnitTestReport("junit", []byte(junit))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(items) != 3 || items[0].Name != "test_solution.test_add" || items[0].Status != "passed" {
+		t.Fatalf("unexpected report: %+v", items)
+		return
+	}
+	if items[1].Status != "failed" || items[1].Message != "assert -1 == 1\ndef test_neg(): ..." {
+		t.Fatalf("unexpected failure: %+v", items[1])
+		return
+	}
+	if items[2].Status != "error" || items[2].Time != 1000.5 {
+		t.Fatalf("unexpected error: %+v", items[2])
+		return
+	}
+
+	// GoogleTest的报告以<testsuites>为根节点，JUnit(surefire)的报告以<testsuite>为根节点
+	items, err = executor.ParseUnitTestReport("", []byte(`<testsuite name="MainTest"><testcase classname="MainTest" name="testSolve"><skipped/></testcase></testsuite>`))
+	if err != nil || len(items) != 1 || items[0].Status != "skipped" {
+		t.Fatalf("unexpected report: %+v, %v", items, err)
+		return
+	}
+
+	items, err = executor.ParseUnitTestReport("json", []byte(`{"tests": [{"name": "add", "status": "passed", "weight": 3}, {"name": "sub", "status": "timeout"}]}`))
+	if err != nil || len(items) != 2 || items[0].Weight != 3 || items[1].Status != "timeout" {
+		t.Fatalf("unexpected report: %+v, %v", items, err)
+		return
+	}
+	if _, err = executor.ParseUnitTestReport("json", []byte(`{"tests": [{"name": "add", "status": "ok"}]}`)); err == nil {
+		t.Fatal("unknown status should be an error")
+		return
+	}
+	if _, err = executor.ParseUnitTestReport("json", []byte(`{"tests": []}`)); err == nil {
+		t.Fatal("empty report should be an error")
+	}
+}
@@ -33,6 +33,14 @@ func initWorkRoot() error {
 	if err != nil {
 		return err
 	}
+	err = provider.PlaceLanguages("./languages.json")
+	if err != nil {
+		return err
+	}
+	err = executor.PlaceSystemTimeouts("./timeouts.json")
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
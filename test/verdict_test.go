@@ -0,0 +1,43 @@
+package test
+
+import (
+	"github.com/LanceLRQ/deer-executor/v2/common/constants"
+	"github.com/LanceLRQ/deer-executor/v2/common/utils"
+	"testing"
+)
+
+// Test: check answer case's expected verdict
+func TestCheckAnswerVerdict(t *testing.T) {
+	AC, WA, TLE, RE := constants.JudgeFlagAC, constants.JudgeFlagWA, constants.JudgeFlagTLE, constants.JudgeFlagRE
+	cases := []struct {
+		expected string
+		flags    []int
+		matched  bool
+	}{
+		{constants.AnswerVerdictMain, []int{AC, AC}, true},
+		{constants.AnswerVerdictAC, []int{AC, WA}, false},
+		{"", []int{}, false},
+		{constants.AnswerVerdictWA, []int{AC, WA}, true},
+		{constants.AnswerVerdictWA, []int{AC, AC}, false},
+		{constants.AnswerVerdictWA, []int{WA, TLE}, false},
+		{constants.AnswerVerdictTLE, []int{AC, AC}, false},
+		{constants.AnswerVerdictTLE, []int{AC, TLE}, true},
+		{constants.AnswerVerdictTLEOrAC, []int{AC, AC}, true},
+		{constants.AnswerVerdictTLEOrAC, []int{TLE, WA}, false},
+		{constants.AnswerVerdictRE, []int{RE, AC}, true},
+	}
+	for key, item := range cases {
+		matched, err := utils.CheckAnswerVerdict(item.expected, item.flags)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if matched != item.matched {
+			t.Fatalf("case #%d: expect %v, got %v", key, item.matched, matched)
+			return
+		}
+	}
+	if _, err := utils.CheckAnswerVerdict("ok", nil); err == nil {
+		t.Fatal("unknown verdict should return an error")
+	}
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bufio"
+	"github.com/LanceLRQ/deer-executor/v2/common/provider"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const zygoteCode = `import os, signal, sys, time
+counter = 0
+for line in sys.stdin:
+    if line.strip() == "forge":
+        # 尝试通过/proc伪造预热进程的回复
+        try:
+            fd = os.open("/proc/%d/fd/4" % os.getppid(), os.O_WRONLY)
+            os.write(fd, b'{"status": 0, "utime": 0}\n')
+            print("forged")
+        except OSError:
+            print("blocked")
+        sys.exit(0)
+    if line.strip() == "sleep":
+        signal.signal(signal.SIGALRM, signal.SIG_IGN)
+        time.sleep(60)
+    counter += sum(int(x) for x in line.split())
+print(counter)
+if counter < 0:
+    sys.exit(3)
+`
+
+// Test: python zygote worker forks a clean child for each test case
+func TestPythonZygoteWorker(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not found")
+		return
+	}
+	workDir, err := ioutil.TempDir("", "deer-zygote-")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	compiler, err := provider.NewCompileProvider("python3")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err = compiler.Init(zygoteCode, workDir); err != nil {
+		t.Fatal(err)
+		return
+	}
+	args, err := compiler.GetZygoteArgs()
+	if err != nil || len(args) == 0 {
+		t.Fatalf("get zygote args failed: %v, %v", args, err)
+		return
+	}
+
+	requestRead, requestWrite, _ := os.Pipe()
+	responseRead, responseWrite, _ := os.Pipe()
+	worker := exec.Command(args[0], args[1:]...)
+	worker.ExtraFiles = []*os.File{requestRead, responseWrite} // fd 3, 4
+	if err = worker.Start(); err != nil {
+		t.Fatal(err)
+		return
+	}
+	_ = requestRead.Close()
+	_ = responseWrite.Close()
+	defer worker.Wait()
+	defer requestWrite.Close()
+
+	responses := bufio.NewReader(responseRead)
+	ready, err := provider.ReadZygoteResponse(responses)
+	if err != nil || !ready.Ready {
+		t.Fatalf("zygote not ready: %v, %v", ready, err)
+		return
+	}
+
+	// 每个请求先回复子进程的pid，再回复运行结果，两个回复都带有请求的nonce
+	runCase := func(nonce string, input string, started func(pid int)) (*provider.ZygoteResponse, error) {
+		_ = ioutil.WriteFile(path.Join(workDir, "in.txt"), []byte(input), 0644)
+		err := provider.WriteZygoteRequest(requestWrite, &provider.ZygoteRequest{
+			Nonce:       nonce,
+			Stdin:       path.Join(workDir, "in.txt"),
+			Stdout:      path.Join(workDir, "out.txt"),
+			Stderr:      path.Join(workDir, "err.txt"),
+			TimeLimit:   1000,
+			MemoryLimit: 262144,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response, err := provider.ReadZygoteResponse(responses)
+		if err != nil {
+			return nil, err
+		}
+		if response.Nonce != nonce || response.Pid <= 0 {
+			return nil, errors.Errorf("unexpected pid response: %+v", response)
+		}
+		if started != nil {
+			started(response.Pid)
+		}
+		response, err = provider.ReadZygoteResponse(responses)
+		if err == nil && response.Nonce != nonce {
+			return nil, errors.Errorf("unexpected nonce: %s", response.Nonce)
+		}
+		return response, err
+	}
+
+	cases := []struct {
+		Input  string
+		Output string
+		Exit   int
+	}{
+		{"1 2\n", "3\n", 0},
+		{"10 20\n30\n", "60\n", 0}, // counter should not be kept from the last case
+		{"-5\n", "-5\n", 3},
+	}
+	for i, item := range cases {
+		response, err := runCase(strconv.Itoa(i), item.Input, nil)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		status := syscall.WaitStatus(response.Status)
+		output, _ := ioutil.ReadFile(path.Join(workDir, "out.txt"))
+		if !status.Exited() || status.ExitStatus() != item.Exit || string(output) != item.Output {
+			errmsg, _ := ioutil.ReadFile(path.Join(workDir, "err.txt"))
+			t.Fatalf("case %d: exit %d, output %q, stderr: %s", i, status.ExitStatus(), output, strings.TrimSpace(string(errmsg)))
+			return
+		}
+	}
+
+	// 子进程不能访问预热进程的控制管道 (root有CAP_SYS_PTRACE，不受限制)
+	if os.Geteuid() != 0 {
+		response, err := runCase("forge", "forge\n", nil)
+		output, _ := ioutil.ReadFile(path.Join(workDir, "out.txt"))
+		if err != nil || string(output) != "blocked\n" {
+			t.Fatalf("child should not reach the response pipe: %q, %v", output, err)
+			return
+		}
+		if status := syscall.WaitStatus(response.Status); !status.Exited() || status.ExitStatus() != 0 {
+			t.Fatalf("unexpected status: %d", response.Status)
+			return
+		}
+	}
+
+	// 子进程的pid用于评测端按真实时间限制结束子进程 (代码可以取消自己的定时器)
+	response, err := runCase("sleep", "sleep\n", func(pid int) {
+		time.Sleep(200 * time.Millisecond)
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if status := syscall.WaitStatus(response.Status); !status.Signaled() || status.Signal() != syscall.SIGKILL {
+		t.Fatalf("child should be killed, got status %d", response.Status)
+	}
+}